@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -50,6 +52,18 @@ func (vp *ClusterVectorPipeline) SetReason(reason *string) {
 	vp.Status.Reason = reason
 }
 
+func (vp *ClusterVectorPipeline) SetCondition(conditionType string, status metav1.ConditionStatus, reason, message string) {
+	vp.Status.SetCondition(conditionType, status, reason, message, vp.Generation)
+}
+
+func (vp *ClusterVectorPipeline) SetRenderedConfigs(configs []RenderedConfig) {
+	vp.Status.RenderedConfigs = configs
+}
+
+func (vp *ClusterVectorPipeline) SetConfigErrors(errs []ConfigError) {
+	vp.Status.ConfigErrors = errs
+}
+
 func (vp *ClusterVectorPipeline) GetLastAppliedPipeline() *uint32 {
 	return vp.Status.LastAppliedPipelineHash
 }
@@ -58,6 +72,14 @@ func (vp *ClusterVectorPipeline) SetLastAppliedPipeline(hash *uint32) {
 	vp.Status.LastAppliedPipelineHash = hash
 }
 
+func (vp *ClusterVectorPipeline) GetAppliedSpec() *runtime.RawExtension {
+	return vp.Status.AppliedSpec
+}
+
+func (vp *ClusterVectorPipeline) SetAppliedSpec(spec *runtime.RawExtension) {
+	vp.Status.AppliedSpec = spec
+}
+
 func (vp *ClusterVectorPipeline) UpdateStatus(ctx context.Context, c client.Client) error {
 	return k8s.UpdateStatus(ctx, vp, c)
 }
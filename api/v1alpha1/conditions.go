@@ -0,0 +1,117 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition types
+const (
+	// ConditionReady is True when all other conditions required for the object are satisfied
+	ConditionReady = "Ready"
+	// ConditionConfigValid is True when config passed config check
+	ConditionConfigValid = "ConfigValid"
+	// ConditionApplied is True when last valid config is applied to Vector
+	ConditionApplied = "Applied"
+	// ConditionAgentReady is True when all Vector Agent pods are ready
+	ConditionAgentReady = "AgentReady"
+	// ConditionDegraded is True when Vector works, but not with desired config
+	ConditionDegraded = "Degraded"
+	// ConditionTerminating is True while operator removes Vector resources before deletion
+	ConditionTerminating = "Terminating"
+)
+
+// Condition reasons
+const (
+	ReasonConfigCheckPassed       = "ConfigCheckPassed"
+	ReasonValidationFailed        = "ValidationFailed"
+	ReasonConfigCheckTimeout      = "ConfigCheckTimeout"
+	ReasonConfigBuildFailed       = "ConfigBuildFailed"
+	ReasonNamespaceScopeViolation = "NamespaceScopeViolation"
+	ReasonSourceTypeNotAllowed    = "SourceTypeNotAllowed"
+	ReasonAggregatorNotEnabled    = "AggregatorNotEnabled"
+	ReasonInvalidTopology         = "InvalidTopology"
+	ReasonConfigApplied           = "ConfigApplied"
+	ReasonPodsReady               = "PodsReady"
+	ReasonPodsNotReady            = "PodsNotReady"
+	ReasonAsExpected              = "AsExpected"
+	ReasonRolloutInProgress       = "RolloutInProgress"
+	ReasonCrashLoopBackOff        = "CrashLoopBackOff"
+	ReasonConditionNotMet         = "ConditionNotMet"
+	ReasonReady                   = "Ready"
+	ReasonDryRun                  = "DryRun"
+	ReasonInvalidValueRef         = "InvalidValueRef"
+	ReasonValueRefNotFound        = "ValueRefNotFound"
+	ReasonEnvNotAllowed           = "EnvNotAllowed"
+	ReasonCleanupInProgress       = "CleanupInProgress"
+	ReasonCleanupFailed           = "CleanupFailed"
+	ReasonVectorConfigCheckFailed = "VectorConfigCheckFailed"
+	// ReasonConfigCheckInfrastructureFailure is set, when configcheck pod can't run, so config is not checked
+	ReasonConfigCheckInfrastructureFailure = "ConfigCheckInfrastructureFailure"
+	// ReasonInvalidComponent is set, when typed component can't be converted to Vector component
+	ReasonInvalidComponent = "InvalidComponent"
+	// ReasonInvalidTemplate is set, when template transform can't be expanded
+	ReasonInvalidTemplate = "InvalidTemplate"
+)
+
+var (
+	vectorReadyConditions   = []string{ConditionConfigValid, ConditionApplied, ConditionAgentReady}
+	pipelineReadyConditions = []string{ConditionConfigValid}
+)
+
+// SetCondition sets Vector status condition and recalculates Ready condition
+func (v *Vector) SetCondition(conditionType string, status metav1.ConditionStatus, reason, message string) {
+	v.Status.ObservedGeneration = v.Generation
+	setCondition(&v.Status.Conditions, conditionType, status, reason, message, v.Generation)
+	setReadyCondition(&v.Status.Conditions, vectorReadyConditions, v.Generation)
+}
+
+// SetCondition sets pipeline status condition and recalculates Ready condition
+func (s *VectorPipelineStatus) SetCondition(conditionType string, status metav1.ConditionStatus, reason, message string, generation int64) {
+	s.ObservedGeneration = generation
+	setCondition(&s.Conditions, conditionType, status, reason, message, generation)
+	setReadyCondition(&s.Conditions, pipelineReadyConditions, generation)
+}
+
+func setCondition(conditions *[]metav1.Condition, conditionType string, status metav1.ConditionStatus, reason, message string, generation int64) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+func setReadyCondition(conditions *[]metav1.Condition, required []string, generation int64) {
+	for _, t := range required {
+		c := meta.FindStatusCondition(*conditions, t)
+		if c == nil {
+			setCondition(conditions, ConditionReady, metav1.ConditionUnknown, ReasonConditionNotMet, fmt.Sprintf("%s condition is not reported yet", t), generation)
+			return
+		}
+		if c.Status != metav1.ConditionTrue {
+			setCondition(conditions, ConditionReady, metav1.ConditionFalse, c.Reason, fmt.Sprintf("%s condition is %s", t, c.Status), generation)
+			return
+		}
+	}
+	setCondition(conditions, ConditionReady, metav1.ConditionTrue, ReasonReady, "", generation)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVectorReadyCondition(t *testing.T) {
+	type condition struct {
+		conditionType string
+		status        metav1.ConditionStatus
+		reason        string
+	}
+
+	type testCase struct {
+		name       string
+		conditions []condition
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}
+
+	cases := []testCase{
+		{
+			name: "Not all conditions reported",
+			conditions: []condition{
+				{vectorv1alpha1.ConditionConfigValid, metav1.ConditionTrue, vectorv1alpha1.ReasonConfigCheckPassed},
+			},
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: vectorv1alpha1.ReasonConditionNotMet,
+		},
+		{
+			name: "Config invalid",
+			conditions: []condition{
+				{vectorv1alpha1.ConditionConfigValid, metav1.ConditionFalse, vectorv1alpha1.ReasonValidationFailed},
+				{vectorv1alpha1.ConditionApplied, metav1.ConditionTrue, vectorv1alpha1.ReasonConfigApplied},
+				{vectorv1alpha1.ConditionAgentReady, metav1.ConditionTrue, vectorv1alpha1.ReasonPodsReady},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: vectorv1alpha1.ReasonValidationFailed,
+		},
+		{
+			name: "All conditions true",
+			conditions: []condition{
+				{vectorv1alpha1.ConditionConfigValid, metav1.ConditionTrue, vectorv1alpha1.ReasonConfigCheckPassed},
+				{vectorv1alpha1.ConditionApplied, metav1.ConditionTrue, vectorv1alpha1.ReasonConfigApplied},
+				{vectorv1alpha1.ConditionAgentReady, metav1.ConditionTrue, vectorv1alpha1.ReasonPodsReady},
+			},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: vectorv1alpha1.ReasonReady,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := &vectorv1alpha1.Vector{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+			}
+			for _, c := range tc.conditions {
+				v.SetCondition(c.conditionType, c.status, c.reason, "")
+			}
+			ready := meta.FindStatusCondition(v.Status.Conditions, vectorv1alpha1.ConditionReady)
+			require.NotNil(t, ready)
+			require.Equal(t, tc.wantStatus, ready.Status)
+			require.Equal(t, tc.wantReason, ready.Reason)
+			require.Equal(t, int64(2), v.Status.ObservedGeneration)
+		})
+	}
+}
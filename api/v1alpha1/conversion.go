@@ -0,0 +1,28 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// v1alpha1 is storage and hub version: other versions are converted to and from it by conversion webhook
+
+// Hub marks Vector as conversion hub
+func (*Vector) Hub() {}
+
+// Hub marks VectorPipeline as conversion hub
+func (*VectorPipeline) Hub() {}
+
+// Hub marks ClusterVectorPipeline as conversion hub
+func (*ClusterVectorPipeline) Hub() {}
@@ -0,0 +1,13 @@
+package v1alpha1
+
+// DefaultsVersionAnnotation is set by defaulting webhook to operator version, that applied spec defaults
+const DefaultsVersionAnnotation = "observability.kaasops.io/defaults-version"
+
+// VectorFinalizer blocks Vector deletion until operator removes resources, that can't be garbage-collected
+// by owner reference: cluster-scoped RBAC and config check resources
+const VectorFinalizer = "observability.kaasops.io/cleanup"
+
+// IsAggregatorEnabled returns true if Vector Aggregator is deployed for Vector
+func (v *Vector) IsAggregatorEnabled() bool {
+	return v.Spec.Aggregator != nil && v.Spec.Aggregator.Enable
+}
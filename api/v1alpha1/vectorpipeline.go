@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -11,6 +13,14 @@ var (
 	LocalPipelineKind = "VectorPipeline"
 )
 
+// GetRole returns pipeline role, agent if not specified
+func (spec VectorPipelineSpec) GetRole() string {
+	if spec.Role == "" {
+		return PipelineRoleAgent
+	}
+	return spec.Role
+}
+
 func (vp *VectorPipeline) GetSpec() VectorPipelineSpec {
 	return vp.Spec
 }
@@ -50,6 +60,18 @@ func (vp *VectorPipeline) SetReason(reason *string) {
 	vp.Status.Reason = reason
 }
 
+func (vp *VectorPipeline) SetCondition(conditionType string, status metav1.ConditionStatus, reason, message string) {
+	vp.Status.SetCondition(conditionType, status, reason, message, vp.Generation)
+}
+
+func (vp *VectorPipeline) SetRenderedConfigs(configs []RenderedConfig) {
+	vp.Status.RenderedConfigs = configs
+}
+
+func (vp *VectorPipeline) SetConfigErrors(errs []ConfigError) {
+	vp.Status.ConfigErrors = errs
+}
+
 func (vp *VectorPipeline) GetLastAppliedPipeline() *uint32 {
 	return vp.Status.LastAppliedPipelineHash
 }
@@ -58,6 +80,14 @@ func (vp *VectorPipeline) SetLastAppliedPipeline(hash *uint32) {
 	vp.Status.LastAppliedPipelineHash = hash
 }
 
+func (vp *VectorPipeline) GetAppliedSpec() *runtime.RawExtension {
+	return vp.Status.AppliedSpec
+}
+
+func (vp *VectorPipeline) SetAppliedSpec(spec *runtime.RawExtension) {
+	vp.Status.AppliedSpec = spec
+}
+
 func (vp *VectorPipeline) UpdateStatus(ctx context.Context, c client.Client) error {
 	return k8s.UpdateStatus(ctx, vp, c)
 }
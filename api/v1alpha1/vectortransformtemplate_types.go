@@ -0,0 +1,76 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// VectorTransformTemplateSpec defines transforms, that pipelines reference with template transform
+type VectorTransformTemplateSpec struct {
+	// Parameters are values, that pipelines set in template transform. Parameter is used in transforms as $(name)
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Parameters []TransformTemplateParameter `json:"parameters,omitempty"`
+	// Transforms are Vector transforms. Inputs are names of other transforms of template.
+	// Transforms without inputs receive events from inputs of template transform
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Transforms *runtime.RawExtension `json:"transforms"`
+	// Output is name of transform, that sends events to pipeline components. Can be omitted, if template has single transform
+	// +optional
+	Output string `json:"output,omitempty"`
+}
+
+// TransformTemplateParameter defines template parameter
+type TransformTemplateParameter struct {
+	// Name is parameter name
+	// +kubebuilder:validation:Pattern=`^[A-Za-z_][A-Za-z0-9_]*$`
+	Name string `json:"name"`
+	// Description describes parameter for template users
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Default is used, if pipeline doesn't set parameter. Parameter without default is required
+	// +optional
+	Default *string `json:"default,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster,shortName=vtt,categories=all
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// VectorTransformTemplate is the Schema for the vectortransformtemplates API
+type VectorTransformTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VectorTransformTemplateSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// VectorTransformTemplateList contains a list of VectorTransformTemplate
+type VectorTransformTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VectorTransformTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VectorTransformTemplate{}, &VectorTransformTemplateList{})
+}
@@ -22,7 +22,9 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -110,20 +112,20 @@ func (in *ConfigCheck) DeepCopyInto(out *ConfigCheck) {
 	}
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = new([]v1.Toleration)
+		*out = new([]corev1.Toleration)
 		if **in != nil {
 			in, out := *in, *out
-			*out = make([]v1.Toleration, len(*in))
+			*out = make([]corev1.Toleration, len(*in))
 			for i := range *in {
 				(*in)[i].DeepCopyInto(&(*out)[i])
 			}
@@ -141,6 +143,850 @@ func (in *ConfigCheck) DeepCopy() *ConfigCheck {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigError) DeepCopyInto(out *ConfigError) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigError.
+func (in *ConfigError) DeepCopy() *ConfigError {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchBulk) DeepCopyInto(out *ElasticsearchBulk) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchBulk.
+func (in *ElasticsearchBulk) DeepCopy() *ElasticsearchBulk {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchBulk)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchSink) DeepCopyInto(out *ElasticsearchSink) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Bulk != nil {
+		in, out := &in.Bulk, &out.Bulk
+		*out = new(ElasticsearchBulk)
+		**out = **in
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(SinkAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(SinkTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Batch != nil {
+		in, out := &in.Batch, &out.Batch
+		*out = new(SinkBatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Buffer != nil {
+		in, out := &in.Buffer, &out.Buffer
+		*out = new(SinkBuffer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Healthcheck != nil {
+		in, out := &in.Healthcheck, &out.Healthcheck
+		*out = new(SinkHealthcheck)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchSink.
+func (in *ElasticsearchSink) DeepCopy() *ElasticsearchSink {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvAllowlistRule) DeepCopyInto(out *EnvAllowlistRule) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvAllowlistRule.
+func (in *EnvAllowlistRule) DeepCopy() *EnvAllowlistRule {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvAllowlistRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExcludedPipeline) DeepCopyInto(out *ExcludedPipeline) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExcludedPipeline.
+func (in *ExcludedPipeline) DeepCopy() *ExcludedPipeline {
+	if in == nil {
+		return nil
+	}
+	out := new(ExcludedPipeline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilterTransform) DeepCopyInto(out *FilterTransform) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilterTransform.
+func (in *FilterTransform) DeepCopy() *FilterTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(FilterTransform)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPSink) DeepCopyInto(out *HTTPSink) {
+	*out = *in
+	in.Encoding.DeepCopyInto(&out.Encoding)
+	if in.Framing != nil {
+		in, out := &in.Framing, &out.Framing
+		*out = new(SinkFraming)
+		**out = **in
+	}
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		*out = new(SinkRequest)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(SinkAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(SinkTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Batch != nil {
+		in, out := &in.Batch, &out.Batch
+		*out = new(SinkBatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Buffer != nil {
+		in, out := &in.Buffer, &out.Buffer
+		*out = new(SinkBuffer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Healthcheck != nil {
+		in, out := &in.Healthcheck, &out.Healthcheck
+		*out = new(SinkHealthcheck)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPSink.
+func (in *HTTPSink) DeepCopy() *HTTPSink {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaSASL) DeepCopyInto(out *KafkaSASL) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Password != nil {
+		in, out := &in.Password, &out.Password
+		*out = new(v1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaSASL.
+func (in *KafkaSASL) DeepCopy() *KafkaSASL {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaSASL)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaSink) DeepCopyInto(out *KafkaSink) {
+	*out = *in
+	in.Encoding.DeepCopyInto(&out.Encoding)
+	if in.MessageTimeoutMs != nil {
+		in, out := &in.MessageTimeoutMs, &out.MessageTimeoutMs
+		*out = new(int64)
+		**out = **in
+	}
+	if in.SocketTimeoutMs != nil {
+		in, out := &in.SocketTimeoutMs, &out.SocketTimeoutMs
+		*out = new(int64)
+		**out = **in
+	}
+	if in.LibrdkafkaOptions != nil {
+		in, out := &in.LibrdkafkaOptions, &out.LibrdkafkaOptions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SASL != nil {
+		in, out := &in.SASL, &out.SASL
+		*out = new(KafkaSASL)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(KafkaTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Buffer != nil {
+		in, out := &in.Buffer, &out.Buffer
+		*out = new(SinkBuffer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Healthcheck != nil {
+		in, out := &in.Healthcheck, &out.Healthcheck
+		*out = new(SinkHealthcheck)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaSink.
+func (in *KafkaSink) DeepCopy() *KafkaSink {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaTLS) DeepCopyInto(out *KafkaTLS) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	in.SinkTLS.DeepCopyInto(&out.SinkTLS)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTLS.
+func (in *KafkaTLS) DeepCopy() *KafkaTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernetesLogsSource) DeepCopyInto(out *KubernetesLogsSource) {
+	*out = *in
+	if in.AutoPartialMerge != nil {
+		in, out := &in.AutoPartialMerge, &out.AutoPartialMerge
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExcludePathsGlobPatterns != nil {
+		in, out := &in.ExcludePathsGlobPatterns, &out.ExcludePathsGlobPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IncludePathsGlobPatterns != nil {
+		in, out := &in.IncludePathsGlobPatterns, &out.IncludePathsGlobPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxLineBytes != nil {
+		in, out := &in.MaxLineBytes, &out.MaxLineBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.GlobMinimumCooldownMs != nil {
+		in, out := &in.GlobMinimumCooldownMs, &out.GlobMinimumCooldownMs
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesLogsSource.
+func (in *KubernetesLogsSource) DeepCopy() *KubernetesLogsSource {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernetesLogsSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LokiSink) DeepCopyInto(out *LokiSink) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RemoveLabelFields != nil {
+		in, out := &in.RemoveLabelFields, &out.RemoveLabelFields
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RemoveTimestamp != nil {
+		in, out := &in.RemoveTimestamp, &out.RemoveTimestamp
+		*out = new(bool)
+		**out = **in
+	}
+	in.Encoding.DeepCopyInto(&out.Encoding)
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(SinkAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(SinkTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Batch != nil {
+		in, out := &in.Batch, &out.Batch
+		*out = new(SinkBatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Buffer != nil {
+		in, out := &in.Buffer, &out.Buffer
+		*out = new(SinkBuffer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Healthcheck != nil {
+		in, out := &in.Healthcheck, &out.Healthcheck
+		*out = new(SinkHealthcheck)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LokiSink.
+func (in *LokiSink) DeepCopy() *LokiSink {
+	if in == nil {
+		return nil
+	}
+	out := new(LokiSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusRemoteWriteSink) DeepCopyInto(out *PrometheusRemoteWriteSink) {
+	*out = *in
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		*out = new(SinkRequest)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(SinkAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(SinkTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Batch != nil {
+		in, out := &in.Batch, &out.Batch
+		*out = new(SinkBatch)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Buffer != nil {
+		in, out := &in.Buffer, &out.Buffer
+		*out = new(SinkBuffer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Healthcheck != nil {
+		in, out := &in.Healthcheck, &out.Healthcheck
+		*out = new(SinkHealthcheck)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusRemoteWriteSink.
+func (in *PrometheusRemoteWriteSink) DeepCopy() *PrometheusRemoteWriteSink {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusRemoteWriteSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemapTransform) DeepCopyInto(out *RemapTransform) {
+	*out = *in
+	if in.DropOnError != nil {
+		in, out := &in.DropOnError, &out.DropOnError
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DropOnAbort != nil {
+		in, out := &in.DropOnAbort, &out.DropOnAbort
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RerouteDropped != nil {
+		in, out := &in.RerouteDropped, &out.RerouteDropped
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemapTransform.
+func (in *RemapTransform) DeepCopy() *RemapTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(RemapTransform)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenderedConfig) DeepCopyInto(out *RenderedConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenderedConfig.
+func (in *RenderedConfig) DeepCopy() *RenderedConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RenderedConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteTransform) DeepCopyInto(out *RouteTransform) {
+	*out = *in
+	if in.Route != nil {
+		in, out := &in.Route, &out.Route
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteTransform.
+func (in *RouteTransform) DeepCopy() *RouteTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(RouteTransform)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SinkAuth) DeepCopyInto(out *SinkAuth) {
+	*out = *in
+	if in.Password != nil {
+		in, out := &in.Password, &out.Password
+		*out = new(v1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Token != nil {
+		in, out := &in.Token, &out.Token
+		*out = new(v1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SinkAuth.
+func (in *SinkAuth) DeepCopy() *SinkAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(SinkAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SinkBatch) DeepCopyInto(out *SinkBatch) {
+	*out = *in
+	if in.MaxBytes != nil {
+		in, out := &in.MaxBytes, &out.MaxBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxEvents != nil {
+		in, out := &in.MaxEvents, &out.MaxEvents
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TimeoutSecs != nil {
+		in, out := &in.TimeoutSecs, &out.TimeoutSecs
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SinkBatch.
+func (in *SinkBatch) DeepCopy() *SinkBatch {
+	if in == nil {
+		return nil
+	}
+	out := new(SinkBatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SinkBuffer) DeepCopyInto(out *SinkBuffer) {
+	*out = *in
+	if in.MaxEvents != nil {
+		in, out := &in.MaxEvents, &out.MaxEvents
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SinkBuffer.
+func (in *SinkBuffer) DeepCopy() *SinkBuffer {
+	if in == nil {
+		return nil
+	}
+	out := new(SinkBuffer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SinkEncoding) DeepCopyInto(out *SinkEncoding) {
+	*out = *in
+	if in.OnlyFields != nil {
+		in, out := &in.OnlyFields, &out.OnlyFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExceptFields != nil {
+		in, out := &in.ExceptFields, &out.ExceptFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SinkEncoding.
+func (in *SinkEncoding) DeepCopy() *SinkEncoding {
+	if in == nil {
+		return nil
+	}
+	out := new(SinkEncoding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SinkFraming) DeepCopyInto(out *SinkFraming) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SinkFraming.
+func (in *SinkFraming) DeepCopy() *SinkFraming {
+	if in == nil {
+		return nil
+	}
+	out := new(SinkFraming)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SinkHealthcheck) DeepCopyInto(out *SinkHealthcheck) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SinkHealthcheck.
+func (in *SinkHealthcheck) DeepCopy() *SinkHealthcheck {
+	if in == nil {
+		return nil
+	}
+	out := new(SinkHealthcheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SinkRequest) DeepCopyInto(out *SinkRequest) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TimeoutSecs != nil {
+		in, out := &in.TimeoutSecs, &out.TimeoutSecs
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RetryAttempts != nil {
+		in, out := &in.RetryAttempts, &out.RetryAttempts
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SinkRequest.
+func (in *SinkRequest) DeepCopy() *SinkRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(SinkRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SinkSpec) DeepCopyInto(out *SinkSpec) {
+	*out = *in
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Elasticsearch != nil {
+		in, out := &in.Elasticsearch, &out.Elasticsearch
+		*out = new(ElasticsearchSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Loki != nil {
+		in, out := &in.Loki, &out.Loki
+		*out = new(LokiSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kafka != nil {
+		in, out := &in.Kafka, &out.Kafka
+		*out = new(KafkaSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrometheusRemoteWrite != nil {
+		in, out := &in.PrometheusRemoteWrite, &out.PrometheusRemoteWrite
+		*out = new(PrometheusRemoteWriteSink)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SinkSpec.
+func (in *SinkSpec) DeepCopy() *SinkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SinkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SinkTLS) DeepCopyInto(out *SinkTLS) {
+	*out = *in
+	if in.KeyPass != nil {
+		in, out := &in.KeyPass, &out.KeyPass
+		*out = new(v1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VerifyCertificate != nil {
+		in, out := &in.VerifyCertificate, &out.VerifyCertificate
+		*out = new(bool)
+		**out = **in
+	}
+	if in.VerifyHostname != nil {
+		in, out := &in.VerifyHostname, &out.VerifyHostname
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SinkTLS.
+func (in *SinkTLS) DeepCopy() *SinkTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(SinkTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SourceSpec) DeepCopyInto(out *SourceSpec) {
+	*out = *in
+	if in.KubernetesLogs != nil {
+		in, out := &in.KubernetesLogs, &out.KubernetesLogs
+		*out = new(KubernetesLogsSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SourceSpec.
+func (in *SourceSpec) DeepCopy() *SourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateTransform) DeepCopyInto(out *TemplateTransform) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateTransform.
+func (in *TemplateTransform) DeepCopy() *TemplateTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateTransform)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransformSpec) DeepCopyInto(out *TransformSpec) {
+	*out = *in
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Remap != nil {
+		in, out := &in.Remap, &out.Remap
+		*out = new(RemapTransform)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Route != nil {
+		in, out := &in.Route, &out.Route
+		*out = new(RouteTransform)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Filter != nil {
+		in, out := &in.Filter, &out.Filter
+		*out = new(FilterTransform)
+		**out = **in
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(TemplateTransform)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransformSpec.
+func (in *TransformSpec) DeepCopy() *TransformSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TransformSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransformTemplateParameter) DeepCopyInto(out *TransformTemplateParameter) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransformTemplateParameter.
+func (in *TransformTemplateParameter) DeepCopy() *TransformTemplateParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(TransformTemplateParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Vector) DeepCopyInto(out *Vector) {
 	*out = *in
@@ -173,30 +1019,30 @@ func (in *VectorAgent) DeepCopyInto(out *VectorAgent) {
 	*out = *in
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]v1.LocalObjectReference, len(*in))
+		*out = make([]corev1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.SecurityContext != nil {
 		in, out := &in.SecurityContext, &out.SecurityContext
-		*out = new(v1.PodSecurityContext)
+		*out = new(corev1.PodSecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.ContainerSecurityContext != nil {
 		in, out := &in.ContainerSecurityContext, &out.ContainerSecurityContext
-		*out = new(v1.SecurityContext)
+		*out = new(corev1.SecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.RuntimeClassName != nil {
@@ -206,14 +1052,14 @@ func (in *VectorAgent) DeepCopyInto(out *VectorAgent) {
 	}
 	if in.HostAliases != nil {
 		in, out := &in.HostAliases, &out.HostAliases
-		*out = make([]v1.HostAlias, len(*in))
+		*out = make([]corev1.HostAlias, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
-		*out = make([]v1.EnvVar, len(*in))
+		*out = make([]corev1.EnvVar, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -221,14 +1067,14 @@ func (in *VectorAgent) DeepCopyInto(out *VectorAgent) {
 	out.Api = in.Api
 	if in.Volumes != nil {
 		in, out := &in.Volumes, &out.Volumes
-		*out = make([]v1.Volume, len(*in))
+		*out = make([]corev1.Volume, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.VolumeMounts != nil {
 		in, out := &in.VolumeMounts, &out.VolumeMounts
-		*out = make([]v1.VolumeMount, len(*in))
+		*out = make([]corev1.VolumeMount, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -247,9 +1093,84 @@ func (in *VectorAgent) DeepCopy() *VectorAgent {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VectorAgentStatus) DeepCopyInto(out *VectorAgentStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VectorAgentStatus.
+func (in *VectorAgentStatus) DeepCopy() *VectorAgentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VectorAgentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VectorAggregator) DeepCopyInto(out *VectorAggregator) {
 	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(corev1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ContainerSecurityContext != nil {
+		in, out := &in.ContainerSecurityContext, &out.ContainerSecurityContext
+		*out = new(corev1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.Api = in.Api
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]corev1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]corev1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DataVolumeClaimTemplate != nil {
+		in, out := &in.DataVolumeClaimTemplate, &out.DataVolumeClaimTemplate
+		*out = new(corev1.PersistentVolumeClaimSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VectorAggregator.
@@ -371,6 +1292,27 @@ func (in *VectorPipelineSpec) DeepCopyInto(out *VectorPipelineSpec) {
 		*out = new(runtime.RawExtension)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TypedSources != nil {
+		in, out := &in.TypedSources, &out.TypedSources
+		*out = make(map[string]SourceSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.TypedTransforms != nil {
+		in, out := &in.TypedTransforms, &out.TypedTransforms
+		*out = make(map[string]TransformSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.TypedSinks != nil {
+		in, out := &in.TypedSinks, &out.TypedSinks
+		*out = make(map[string]SinkSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VectorPipelineSpec.
@@ -401,6 +1343,28 @@ func (in *VectorPipelineStatus) DeepCopyInto(out *VectorPipelineStatus) {
 		*out = new(uint32)
 		**out = **in
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RenderedConfigs != nil {
+		in, out := &in.RenderedConfigs, &out.RenderedConfigs
+		*out = make([]RenderedConfig, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigErrors != nil {
+		in, out := &in.ConfigErrors, &out.ConfigErrors
+		*out = make([]ConfigError, len(*in))
+		copy(*out, *in)
+	}
+	if in.AppliedSpec != nil {
+		in, out := &in.AppliedSpec, &out.AppliedSpec
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VectorPipelineStatus.
@@ -421,6 +1385,28 @@ func (in *VectorSpec) DeepCopyInto(out *VectorSpec) {
 		*out = new(VectorAgent)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Aggregator != nil {
+		in, out := &in.Aggregator, &out.Aggregator
+		*out = new(VectorAggregator)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PipelineSelector != nil {
+		in, out := &in.PipelineSelector, &out.PipelineSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PipelineNamespaceSelector != nil {
+		in, out := &in.PipelineNamespaceSelector, &out.PipelineNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EnvAllowlist != nil {
+		in, out := &in.EnvAllowlist, &out.EnvAllowlist
+		*out = make([]EnvAllowlistRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VectorSpec.
@@ -451,6 +1437,33 @@ func (in *VectorStatus) DeepCopyInto(out *VectorStatus) {
 		*out = new(uint32)
 		**out = **in
 	}
+	if in.LastAppliedAggregatorConfigHash != nil {
+		in, out := &in.LastAppliedAggregatorConfigHash, &out.LastAppliedAggregatorConfigHash
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.Agent != nil {
+		in, out := &in.Agent, &out.Agent
+		*out = new(VectorAgentStatus)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConfigErrors != nil {
+		in, out := &in.ConfigErrors, &out.ConfigErrors
+		*out = make([]ConfigError, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedPipelines != nil {
+		in, out := &in.ExcludedPipelines, &out.ExcludedPipelines
+		*out = make([]ExcludedPipeline, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VectorStatus.
@@ -462,3 +1475,88 @@ func (in *VectorStatus) DeepCopy() *VectorStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VectorTransformTemplate) DeepCopyInto(out *VectorTransformTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VectorTransformTemplate.
+func (in *VectorTransformTemplate) DeepCopy() *VectorTransformTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(VectorTransformTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VectorTransformTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VectorTransformTemplateList) DeepCopyInto(out *VectorTransformTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VectorTransformTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VectorTransformTemplateList.
+func (in *VectorTransformTemplateList) DeepCopy() *VectorTransformTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(VectorTransformTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VectorTransformTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VectorTransformTemplateSpec) DeepCopyInto(out *VectorTransformTemplateSpec) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]TransformTemplateParameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Transforms != nil {
+		in, out := &in.Transforms, &out.Transforms
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VectorTransformTemplateSpec.
+func (in *VectorTransformTemplateSpec) DeepCopy() *VectorTransformTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VectorTransformTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
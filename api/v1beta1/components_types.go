@@ -0,0 +1,468 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// Typed components are converted to Vector components: name of the set component field becomes component type,
+// its fields become component options. Field names match Vector option names. Options, that are not defined
+// here, can be set with raw sources, transforms and sinks.
+
+// SourceSpec is source with validated options. Exactly one component type must be set
+// +kubebuilder:validation:MinProperties=1
+// +kubebuilder:validation:MaxProperties=1
+type SourceSpec struct {
+	// KubernetesLogs collects logs of pods, https://vector.dev/docs/reference/configuration/sources/kubernetes_logs/
+	// +optional
+	KubernetesLogs *KubernetesLogsSource `json:"kubernetes_logs,omitempty"`
+}
+
+// TransformSpec is transform with validated options. Exactly one component type must be set
+// +kubebuilder:validation:MinProperties=2
+// +kubebuilder:validation:MaxProperties=2
+type TransformSpec struct {
+	// Inputs are names of pipeline components, that send events to transform
+	// +kubebuilder:validation:MinItems=1
+	Inputs []string `json:"inputs"`
+	// Remap modifies events with VRL program, https://vector.dev/docs/reference/configuration/transforms/remap/
+	// +optional
+	Remap *RemapTransform `json:"remap,omitempty"`
+	// Route splits events into routes by VRL conditions, https://vector.dev/docs/reference/configuration/transforms/route/
+	// +optional
+	Route *RouteTransform `json:"route,omitempty"`
+	// Filter drops events, that don't match VRL condition, https://vector.dev/docs/reference/configuration/transforms/filter/
+	// +optional
+	Filter *FilterTransform `json:"filter,omitempty"`
+	// Template expands VectorTransformTemplate with parameters
+	// +optional
+	Template *TemplateTransform `json:"template,omitempty"`
+}
+
+// SinkSpec is sink with validated options. Exactly one component type must be set
+// +kubebuilder:validation:MinProperties=2
+// +kubebuilder:validation:MaxProperties=2
+type SinkSpec struct {
+	// Inputs are names of pipeline components, that send events to sink
+	// +kubebuilder:validation:MinItems=1
+	Inputs []string `json:"inputs"`
+	// Elasticsearch sends events to Elasticsearch, https://vector.dev/docs/reference/configuration/sinks/elasticsearch/
+	// +optional
+	Elasticsearch *ElasticsearchSink `json:"elasticsearch,omitempty"`
+	// Loki sends events to Loki, https://vector.dev/docs/reference/configuration/sinks/loki/
+	// +optional
+	Loki *LokiSink `json:"loki,omitempty"`
+	// Kafka sends events to Kafka topic, https://vector.dev/docs/reference/configuration/sinks/kafka/
+	// +optional
+	Kafka *KafkaSink `json:"kafka,omitempty"`
+	// HTTP sends events to HTTP server, https://vector.dev/docs/reference/configuration/sinks/http/
+	// +optional
+	HTTP *HTTPSink `json:"http,omitempty"`
+	// PrometheusRemoteWrite sends metrics with Prometheus remote write protocol,
+	// https://vector.dev/docs/reference/configuration/sinks/prometheus_remote_write/
+	// +optional
+	PrometheusRemoteWrite *PrometheusRemoteWriteSink `json:"prometheus_remote_write,omitempty"`
+}
+
+// KubernetesLogsSource defines options of kubernetes_logs source
+type KubernetesLogsSource struct {
+	// ExtraLabelSelector selects pods by labels
+	// +optional
+	ExtraLabelSelector string `json:"extra_label_selector,omitempty"`
+	// ExtraNamespaceLabelSelector selects pods by labels of namespace. Set by operator for VectorPipeline
+	// +optional
+	ExtraNamespaceLabelSelector string `json:"extra_namespace_label_selector,omitempty"`
+	// ExtraFieldSelector selects pods by fields
+	// +optional
+	ExtraFieldSelector string `json:"extra_field_selector,omitempty"`
+	// AutoPartialMerge merges partial events, split by container runtime. Enabled by default
+	// +optional
+	AutoPartialMerge *bool `json:"auto_partial_merge,omitempty"`
+	// ExcludePathsGlobPatterns are patterns of log files, that are not collected
+	// +optional
+	ExcludePathsGlobPatterns []string `json:"exclude_paths_glob_patterns,omitempty"`
+	// IncludePathsGlobPatterns are patterns of log files, that are collected
+	// +optional
+	IncludePathsGlobPatterns []string `json:"include_paths_glob_patterns,omitempty"`
+	// MaxLineBytes is maximum size of line, longer lines are dropped
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxLineBytes *int64 `json:"max_line_bytes,omitempty"`
+	// GlobMinimumCooldownMs is delay between searches of new log files
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	GlobMinimumCooldownMs *int64 `json:"glob_minimum_cooldown_ms,omitempty"`
+	// IngestionTimestampField is name of field with time, when event was read
+	// +optional
+	IngestionTimestampField string `json:"ingestion_timestamp_field,omitempty"`
+	// Timezone is timezone of timestamps without timezone
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// RemapTransform defines options of remap transform. Either source or file must be set
+type RemapTransform struct {
+	// Source is VRL program
+	// +optional
+	Source string `json:"source,omitempty"`
+	// File is path to file with VRL program in Vector pod
+	// +optional
+	File string `json:"file,omitempty"`
+	// DropOnError drops events, that failed processing
+	// +optional
+	DropOnError *bool `json:"drop_on_error,omitempty"`
+	// DropOnAbort drops events, processing of which was aborted. Enabled by default
+	// +optional
+	DropOnAbort *bool `json:"drop_on_abort,omitempty"`
+	// RerouteDropped sends dropped events to dropped output
+	// +optional
+	RerouteDropped *bool `json:"reroute_dropped,omitempty"`
+	// Timezone is default timezone of VRL functions
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// RouteTransform defines options of route transform
+type RouteTransform struct {
+	// Route maps route names to VRL conditions. Route is used as input with <transform>.<route> name
+	// +kubebuilder:validation:MinProperties=1
+	Route map[string]string `json:"route"`
+}
+
+// FilterTransform defines options of filter transform
+type FilterTransform struct {
+	// Condition is VRL condition, events matching condition are passed
+	// +kubebuilder:validation:MinLength=1
+	Condition string `json:"condition"`
+}
+
+// TemplateTransform references VectorTransformTemplate
+type TemplateTransform struct {
+	// Name is name of VectorTransformTemplate
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Parameters are values of template parameters
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// ElasticsearchSink defines options of elasticsearch sink
+type ElasticsearchSink struct {
+	// Endpoints are Elasticsearch URLs
+	// +kubebuilder:validation:MinItems=1
+	Endpoints []string `json:"endpoints"`
+	// Mode is mode of requests
+	// +kubebuilder:validation:Enum=bulk;data_stream
+	// +optional
+	Mode string `json:"mode,omitempty"`
+	// ApiVersion is Elasticsearch API version. Detected by default
+	// +kubebuilder:validation:Enum=auto;v6;v7;v8
+	// +optional
+	ApiVersion string `json:"api_version,omitempty"`
+	// Bulk defines bulk mode options
+	// +optional
+	Bulk *ElasticsearchBulk `json:"bulk,omitempty"`
+	// IdKey is event field used as document id
+	// +optional
+	IdKey string `json:"id_key,omitempty"`
+	// Pipeline is name of Elasticsearch ingest pipeline
+	// +optional
+	Pipeline string `json:"pipeline,omitempty"`
+	// +kubebuilder:validation:Enum=none;gzip
+	// +optional
+	Compression string `json:"compression,omitempty"`
+	// +optional
+	Auth *SinkAuth `json:"auth,omitempty"`
+	// +optional
+	TLS *SinkTLS `json:"tls,omitempty"`
+	// +optional
+	Batch *SinkBatch `json:"batch,omitempty"`
+	// +optional
+	Buffer *SinkBuffer `json:"buffer,omitempty"`
+	// +optional
+	Healthcheck *SinkHealthcheck `json:"healthcheck,omitempty"`
+}
+
+// ElasticsearchBulk defines options of elasticsearch sink bulk mode
+type ElasticsearchBulk struct {
+	// Index is index name template
+	// +optional
+	Index string `json:"index,omitempty"`
+	// Action is bulk action template
+	// +optional
+	Action string `json:"action,omitempty"`
+}
+
+// LokiSink defines options of loki sink
+type LokiSink struct {
+	// Endpoint is Loki URL
+	// +kubebuilder:validation:MinLength=1
+	Endpoint string `json:"endpoint"`
+	// Path is Loki push API path
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Labels are stream labels, values are templates
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// TenantId is Loki tenant
+	// +optional
+	TenantId string `json:"tenant_id,omitempty"`
+	// OutOfOrderAction defines what to do with events older than the last event of stream
+	// +kubebuilder:validation:Enum=accept;drop;rewrite_timestamp
+	// +optional
+	OutOfOrderAction string `json:"out_of_order_action,omitempty"`
+	// RemoveLabelFields removes fields used in labels from event
+	// +optional
+	RemoveLabelFields *bool `json:"remove_label_fields,omitempty"`
+	// RemoveTimestamp removes timestamp from event
+	// +optional
+	RemoveTimestamp *bool `json:"remove_timestamp,omitempty"`
+	// Encoding defines how events are encoded
+	Encoding SinkEncoding `json:"encoding"`
+	// +kubebuilder:validation:Enum=none;gzip;snappy
+	// +optional
+	Compression string `json:"compression,omitempty"`
+	// +optional
+	Auth *SinkAuth `json:"auth,omitempty"`
+	// +optional
+	TLS *SinkTLS `json:"tls,omitempty"`
+	// +optional
+	Batch *SinkBatch `json:"batch,omitempty"`
+	// +optional
+	Buffer *SinkBuffer `json:"buffer,omitempty"`
+	// +optional
+	Healthcheck *SinkHealthcheck `json:"healthcheck,omitempty"`
+}
+
+// KafkaSink defines options of kafka sink
+type KafkaSink struct {
+	// BootstrapServers is comma separated list of Kafka brokers
+	// +kubebuilder:validation:MinLength=1
+	BootstrapServers string `json:"bootstrap_servers"`
+	// Topic is topic name template
+	// +kubebuilder:validation:MinLength=1
+	Topic string `json:"topic"`
+	// KeyField is event field used as message key
+	// +optional
+	KeyField string `json:"key_field,omitempty"`
+	// HeadersKey is event field with message headers
+	// +optional
+	HeadersKey string `json:"headers_key,omitempty"`
+	// Encoding defines how events are encoded
+	Encoding SinkEncoding `json:"encoding"`
+	// +kubebuilder:validation:Enum=none;gzip;lz4;snappy;zstd
+	// +optional
+	Compression string `json:"compression,omitempty"`
+	// MessageTimeoutMs is local message timeout
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MessageTimeoutMs *int64 `json:"message_timeout_ms,omitempty"`
+	// SocketTimeoutMs is network requests timeout
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	SocketTimeoutMs *int64 `json:"socket_timeout_ms,omitempty"`
+	// LibrdkafkaOptions are advanced librdkafka options
+	// +optional
+	LibrdkafkaOptions map[string]string `json:"librdkafka_options,omitempty"`
+	// +optional
+	SASL *KafkaSASL `json:"sasl,omitempty"`
+	// +optional
+	TLS *KafkaTLS `json:"tls,omitempty"`
+	// +optional
+	Buffer *SinkBuffer `json:"buffer,omitempty"`
+	// +optional
+	Healthcheck *SinkHealthcheck `json:"healthcheck,omitempty"`
+}
+
+// KafkaSASL defines Kafka SASL authentication
+type KafkaSASL struct {
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// +kubebuilder:validation:Enum=PLAIN;SCRAM-SHA-256;SCRAM-SHA-512
+	// +optional
+	Mechanism string `json:"mechanism,omitempty"`
+	// +optional
+	Username string `json:"username,omitempty"`
+	// Password is string or secretRef/configMapRef object
+	// +optional
+	Password *apiextensionsv1.JSON `json:"password,omitempty"`
+}
+
+// KafkaTLS defines TLS options of kafka sink
+type KafkaTLS struct {
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	SinkTLS `json:",inline"`
+}
+
+// HTTPSink defines options of http sink
+type HTTPSink struct {
+	// URI is server URL
+	// +kubebuilder:validation:MinLength=1
+	URI string `json:"uri"`
+	// +kubebuilder:validation:Enum=get;head;post;put;delete;options;trace;patch
+	// +optional
+	Method string `json:"method,omitempty"`
+	// Encoding defines how events are encoded
+	Encoding SinkEncoding `json:"encoding"`
+	// +optional
+	Framing *SinkFraming `json:"framing,omitempty"`
+	// +kubebuilder:validation:Enum=none;gzip;zlib
+	// +optional
+	Compression string `json:"compression,omitempty"`
+	// +optional
+	Request *SinkRequest `json:"request,omitempty"`
+	// +optional
+	Auth *SinkAuth `json:"auth,omitempty"`
+	// +optional
+	TLS *SinkTLS `json:"tls,omitempty"`
+	// +optional
+	Batch *SinkBatch `json:"batch,omitempty"`
+	// +optional
+	Buffer *SinkBuffer `json:"buffer,omitempty"`
+	// +optional
+	Healthcheck *SinkHealthcheck `json:"healthcheck,omitempty"`
+}
+
+// PrometheusRemoteWriteSink defines options of prometheus_remote_write sink
+type PrometheusRemoteWriteSink struct {
+	// Endpoint is remote write URL
+	// +kubebuilder:validation:MinLength=1
+	Endpoint string `json:"endpoint"`
+	// DefaultNamespace is prefix of metrics without namespace
+	// +optional
+	DefaultNamespace string `json:"default_namespace,omitempty"`
+	// TenantId is value of X-Scope-OrgID header
+	// +optional
+	TenantId string `json:"tenant_id,omitempty"`
+	// +optional
+	Request *SinkRequest `json:"request,omitempty"`
+	// +optional
+	Auth *SinkAuth `json:"auth,omitempty"`
+	// +optional
+	TLS *SinkTLS `json:"tls,omitempty"`
+	// +optional
+	Batch *SinkBatch `json:"batch,omitempty"`
+	// +optional
+	Buffer *SinkBuffer `json:"buffer,omitempty"`
+	// +optional
+	Healthcheck *SinkHealthcheck `json:"healthcheck,omitempty"`
+}
+
+// SinkEncoding defines how events are encoded
+type SinkEncoding struct {
+	// +kubebuilder:validation:Enum=json;text;logfmt;raw_message;gelf;native;native_json
+	Codec string `json:"codec"`
+	// OnlyFields are fields, that are encoded
+	// +optional
+	OnlyFields []string `json:"only_fields,omitempty"`
+	// ExceptFields are fields, that are not encoded
+	// +optional
+	ExceptFields []string `json:"except_fields,omitempty"`
+	// +kubebuilder:validation:Enum=rfc3339;unix
+	// +optional
+	TimestampFormat string `json:"timestamp_format,omitempty"`
+}
+
+// SinkFraming defines how encoded events are separated in stream
+type SinkFraming struct {
+	// +kubebuilder:validation:Enum=bytes;character_delimited;length_delimited;newline_delimited
+	Method string `json:"method"`
+}
+
+// SinkAuth defines HTTP authentication
+type SinkAuth struct {
+	// +kubebuilder:validation:Enum=basic;bearer
+	Strategy string `json:"strategy"`
+	// User is user of basic authentication
+	// +optional
+	User string `json:"user,omitempty"`
+	// Password is password of basic authentication: string or secretRef/configMapRef object
+	// +optional
+	Password *apiextensionsv1.JSON `json:"password,omitempty"`
+	// Token is bearer token: string or secretRef/configMapRef object
+	// +optional
+	Token *apiextensionsv1.JSON `json:"token,omitempty"`
+}
+
+// SinkTLS defines TLS options. Files are paths in Vector pod
+type SinkTLS struct {
+	// +optional
+	CAFile string `json:"ca_file,omitempty"`
+	// +optional
+	CrtFile string `json:"crt_file,omitempty"`
+	// +optional
+	KeyFile string `json:"key_file,omitempty"`
+	// KeyPass is passphrase of key file: string or secretRef/configMapRef object
+	// +optional
+	KeyPass *apiextensionsv1.JSON `json:"key_pass,omitempty"`
+	// +optional
+	VerifyCertificate *bool `json:"verify_certificate,omitempty"`
+	// +optional
+	VerifyHostname *bool `json:"verify_hostname,omitempty"`
+}
+
+// SinkBatch defines batching of events
+type SinkBatch struct {
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxBytes *int64 `json:"max_bytes,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxEvents *int64 `json:"max_events,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSecs *int64 `json:"timeout_secs,omitempty"`
+}
+
+// SinkBuffer defines sink buffer
+type SinkBuffer struct {
+	// +kubebuilder:validation:Enum=memory;disk
+	// +optional
+	Type string `json:"type,omitempty"`
+	// MaxEvents is size of memory buffer
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxEvents *int64 `json:"max_events,omitempty"`
+	// MaxSize is size of disk buffer in bytes
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxSize *int64 `json:"max_size,omitempty"`
+	// +kubebuilder:validation:Enum=block;drop_newest
+	// +optional
+	WhenFull string `json:"when_full,omitempty"`
+}
+
+// SinkRequest defines HTTP request options
+type SinkRequest struct {
+	// Headers are additional HTTP headers
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSecs *int64 `json:"timeout_secs,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RetryAttempts *int64 `json:"retry_attempts,omitempty"`
+}
+
+// SinkHealthcheck defines sink health check on Vector start
+type SinkHealthcheck struct {
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1_test
+
+import (
+	"strconv"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	vectorv1beta1 "github.com/kaasops/vector-operator/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestVectorConversion(t *testing.T) {
+	type testCase struct {
+		name   string
+		vector *vectorv1alpha1.Vector
+	}
+
+	cases := []testCase{
+		{
+			name: "Agent with podSecurityPolicyName",
+			vector: &vectorv1alpha1.Vector{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "vector",
+					Namespace:   "default",
+					Annotations: map[string]string{"key": "value"},
+				},
+				Spec: vectorv1alpha1.VectorSpec{
+					Agent: &vectorv1alpha1.VectorAgent{
+						Image:                 "timberio/vector:0.24.0-distroless-libc",
+						PodSecurityPolicyName: "vector-psp",
+						PriorityClassName:     "system-node-critical",
+						HostAliases:           []corev1.HostAlias{{IP: "10.0.0.1", Hostnames: []string{"logs.local"}}},
+						ConfigCheck:           vectorv1alpha1.ConfigCheck{Image: func() *string { i := "timberio/vector:0.24.0-alpine"; return &i }()},
+					},
+					EnvAllowlist: []vectorv1alpha1.EnvAllowlistRule{{Namespaces: []string{"default"}, Env: []string{"TOKEN"}}},
+				},
+				Status: vectorv1alpha1.VectorStatus{
+					LastAppliedConfigHash: func() *uint32 { h := uint32(42); return &h }(),
+					ConfigErrors:          []vectorv1alpha1.ConfigError{{Message: "error"}},
+					ExcludedPipelines:     []vectorv1alpha1.ExcludedPipeline{{Pipeline: "default/pipeline", PipelineHash: 7, Reason: "VectorConfigCheckFailed"}},
+				},
+			},
+		},
+		{
+			name: "Agent and aggregator without annotations",
+			vector: &vectorv1alpha1.Vector{
+				ObjectMeta: metav1.ObjectMeta{Name: "vector", Namespace: "default"},
+				Spec: vectorv1alpha1.VectorSpec{
+					Agent: &vectorv1alpha1.VectorAgent{Image: "timberio/vector:0.24.0-distroless-libc"},
+					Aggregator: &vectorv1alpha1.VectorAggregator{
+						Enable:                  true,
+						Replicas:                func() *int32 { r := int32(2); return &r }(),
+						DataVolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+					},
+				},
+			},
+		},
+		{
+			name: "Empty spec",
+			vector: &vectorv1alpha1.Vector{
+				ObjectMeta: metav1.ObjectMeta{Name: "vector", Namespace: "default"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			beta := &vectorv1beta1.Vector{}
+			require.NoError(t, beta.ConvertFrom(tc.vector.DeepCopy()))
+			if tc.vector.Spec.Agent != nil {
+				require.Equal(t, tc.vector.Spec.Agent.PriorityClassName, beta.Spec.Agent.PriorityClassName)
+				require.Equal(t, tc.vector.Spec.Agent.HostAliases, beta.Spec.Agent.HostAliases)
+				if name := tc.vector.Spec.Agent.PodSecurityPolicyName; name != "" {
+					require.Equal(t, name, beta.Annotations[vectorv1beta1.PodSecurityPolicyNameAnnotation])
+				}
+			}
+
+			alpha := &vectorv1alpha1.Vector{}
+			require.NoError(t, beta.ConvertTo(alpha))
+			require.Equal(t, tc.vector, alpha)
+			_, ok := alpha.Annotations[vectorv1beta1.PodSecurityPolicyNameAnnotation]
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestVectorPipelineConversion(t *testing.T) {
+	hash := uint32(42)
+	spec := vectorv1beta1.VectorPipelineSpec{
+		Sources:    &runtime.RawExtension{Raw: []byte(`{"source":{"type":"kubernetes_logs"}}`)},
+		Transforms: &runtime.RawExtension{Raw: []byte(`{"transform":{"type":"remap","inputs":["source"]}}`)},
+		Sinks:      &runtime.RawExtension{Raw: []byte(`{"sink":{"type":"console","inputs":["transform"]}}`)},
+		TypedSinks: map[string]vectorv1beta1.SinkSpec{
+			"loki": {Inputs: []string{"transform"}, Loki: &vectorv1beta1.LokiSink{
+				Endpoint: "http://loki:3100",
+				Encoding: vectorv1beta1.SinkEncoding{Codec: "json"},
+				TLS:      &vectorv1beta1.SinkTLS{KeyPass: &apiextensionsv1.JSON{Raw: []byte(`{"secretRef":{"name":"tls","key":"pass"}}`)}},
+			}},
+		},
+	}
+	status := vectorv1beta1.VectorPipelineStatus{
+		LastAppliedPipelineHash: &hash,
+		RenderedConfigs:         []vectorv1beta1.RenderedConfig{{Vector: "vector"}},
+		ConfigErrors:            []vectorv1beta1.ConfigError{{Message: "error"}},
+		AppliedSpec:             &runtime.RawExtension{Raw: []byte(`{"sinks":{"sink":{"type":"console","inputs":["source"]}}}`)},
+	}
+
+	t.Run("VectorPipeline", func(t *testing.T) {
+		pipeline := &vectorv1beta1.VectorPipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipeline", Namespace: "default"},
+			Spec:       spec,
+			Status:     status,
+		}
+		alpha := &vectorv1alpha1.VectorPipeline{}
+		require.NoError(t, pipeline.DeepCopy().ConvertTo(alpha))
+		require.Equal(t, &hash, alpha.Status.LastAppliedPipelineHash)
+		require.Equal(t, "http://loki:3100", alpha.Spec.TypedSinks["loki"].Loki.Endpoint)
+
+		beta := &vectorv1beta1.VectorPipeline{}
+		require.NoError(t, beta.ConvertFrom(alpha))
+		require.Equal(t, pipeline, beta)
+	})
+
+	t.Run("ClusterVectorPipeline", func(t *testing.T) {
+		pipeline := &vectorv1beta1.ClusterVectorPipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipeline"},
+			Spec:       spec,
+			Status:     status,
+		}
+		alpha := &vectorv1alpha1.ClusterVectorPipeline{}
+		require.NoError(t, pipeline.DeepCopy().ConvertTo(alpha))
+		require.Equal(t, &hash, alpha.Status.LastAppliedPipelineHash)
+
+		beta := &vectorv1beta1.ClusterVectorPipeline{}
+		require.NoError(t, beta.ConvertFrom(alpha))
+		require.Equal(t, pipeline, beta)
+	})
+}
+
+// Typed components are defined in both versions and converted through JSON, so field, that is added to one version
+// only, is lost in round trip
+func TestTypedComponentsRoundTrip(t *testing.T) {
+	f := fuzz.New().NilChance(0.2).NumElements(1, 2).Funcs(
+		func(j *apiextensionsv1.JSON, c fuzz.Continue) {
+			j.Raw = []byte(strconv.Itoa(c.Intn(1000)))
+		},
+	)
+
+	for i := 0; i < 200; i++ {
+		alpha := &vectorv1alpha1.VectorPipeline{}
+		f.Fuzz(&alpha.Spec.TypedSources)
+		f.Fuzz(&alpha.Spec.TypedTransforms)
+		f.Fuzz(&alpha.Spec.TypedSinks)
+		beta := &vectorv1beta1.VectorPipeline{}
+		require.NoError(t, beta.ConvertFrom(alpha.DeepCopy()))
+		gotAlpha := &vectorv1alpha1.VectorPipeline{}
+		require.NoError(t, beta.ConvertTo(gotAlpha))
+		require.Equal(t, alpha.Spec, gotAlpha.Spec)
+
+		beta = &vectorv1beta1.VectorPipeline{}
+		f.Fuzz(&beta.Spec.TypedSources)
+		f.Fuzz(&beta.Spec.TypedTransforms)
+		f.Fuzz(&beta.Spec.TypedSinks)
+		alpha = &vectorv1alpha1.VectorPipeline{}
+		require.NoError(t, beta.DeepCopy().ConvertTo(alpha))
+		gotBeta := &vectorv1beta1.VectorPipeline{}
+		require.NoError(t, gotBeta.ConvertFrom(alpha))
+		require.Equal(t, beta.Spec, gotBeta.Spec)
+	}
+}
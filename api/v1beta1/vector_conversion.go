@@ -0,0 +1,245 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/kaasops/vector-operator/api/v1alpha1"
+)
+
+// PodSecurityPolicyNameAnnotation keeps v1alpha1 spec.agent.podSecurityPolicyName, that is removed in v1beta1,
+// so Vector is not changed by conversion to v1beta1 and back
+const PodSecurityPolicyNameAnnotation = "observability.kaasops.io/v1alpha1-pod-security-policy-name"
+
+// ConvertTo converts Vector to hub version v1alpha1
+func (src *Vector) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.Vector)
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1alpha1.VectorSpec{
+		MergeKubernetesSources:    src.Spec.MergeKubernetesSources,
+		MergeSinks:                src.Spec.MergeSinks,
+		PipelineSelector:          src.Spec.PipelineSelector,
+		PipelineNamespaceSelector: src.Spec.PipelineNamespaceSelector,
+		DryRun:                    src.Spec.DryRun,
+	}
+	if src.Spec.Agent != nil {
+		dst.Spec.Agent = convertAgentTo(src.Spec.Agent)
+		if name, ok := src.Annotations[PodSecurityPolicyNameAnnotation]; ok {
+			dst.Spec.Agent.PodSecurityPolicyName = name
+			dst.Annotations = withoutAnnotation(src.Annotations, PodSecurityPolicyNameAnnotation)
+		}
+	}
+	if src.Spec.Aggregator != nil {
+		dst.Spec.Aggregator = convertAggregatorTo(src.Spec.Aggregator)
+	}
+	for _, rule := range src.Spec.EnvAllowlist {
+		dst.Spec.EnvAllowlist = append(dst.Spec.EnvAllowlist, v1alpha1.EnvAllowlistRule(rule))
+	}
+
+	dst.Status = v1alpha1.VectorStatus{
+		ConfigCheckResult:               src.Status.ConfigCheckResult,
+		Reason:                          src.Status.Reason,
+		LastAppliedConfigHash:           src.Status.LastAppliedConfigHash,
+		LastAppliedAggregatorConfigHash: src.Status.LastAppliedAggregatorConfigHash,
+		ObservedGeneration:              src.Status.ObservedGeneration,
+		Conditions:                      src.Status.Conditions,
+	}
+	if src.Status.Agent != nil {
+		agent := v1alpha1.VectorAgentStatus(*src.Status.Agent)
+		dst.Status.Agent = &agent
+	}
+	for _, e := range src.Status.ConfigErrors {
+		dst.Status.ConfigErrors = append(dst.Status.ConfigErrors, v1alpha1.ConfigError(e))
+	}
+	for _, e := range src.Status.ExcludedPipelines {
+		dst.Status.ExcludedPipelines = append(dst.Status.ExcludedPipelines, v1alpha1.ExcludedPipeline(e))
+	}
+	return nil
+}
+
+// ConvertFrom converts Vector from hub version v1alpha1
+func (dst *Vector) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.Vector)
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = VectorSpec{
+		MergeKubernetesSources:    src.Spec.MergeKubernetesSources,
+		MergeSinks:                src.Spec.MergeSinks,
+		PipelineSelector:          src.Spec.PipelineSelector,
+		PipelineNamespaceSelector: src.Spec.PipelineNamespaceSelector,
+		DryRun:                    src.Spec.DryRun,
+	}
+	if src.Spec.Agent != nil {
+		dst.Spec.Agent = convertAgentFrom(src.Spec.Agent)
+		if src.Spec.Agent.PodSecurityPolicyName != "" {
+			dst.Annotations = withAnnotation(src.Annotations, PodSecurityPolicyNameAnnotation, src.Spec.Agent.PodSecurityPolicyName)
+		}
+	}
+	if src.Spec.Aggregator != nil {
+		dst.Spec.Aggregator = convertAggregatorFrom(src.Spec.Aggregator)
+	}
+	for _, rule := range src.Spec.EnvAllowlist {
+		dst.Spec.EnvAllowlist = append(dst.Spec.EnvAllowlist, EnvAllowlistRule(rule))
+	}
+
+	dst.Status = VectorStatus{
+		ConfigCheckResult:               src.Status.ConfigCheckResult,
+		Reason:                          src.Status.Reason,
+		LastAppliedConfigHash:           src.Status.LastAppliedConfigHash,
+		LastAppliedAggregatorConfigHash: src.Status.LastAppliedAggregatorConfigHash,
+		ObservedGeneration:              src.Status.ObservedGeneration,
+		Conditions:                      src.Status.Conditions,
+	}
+	if src.Status.Agent != nil {
+		agent := VectorAgentStatus(*src.Status.Agent)
+		dst.Status.Agent = &agent
+	}
+	for _, e := range src.Status.ConfigErrors {
+		dst.Status.ConfigErrors = append(dst.Status.ConfigErrors, ConfigError(e))
+	}
+	for _, e := range src.Status.ExcludedPipelines {
+		dst.Status.ExcludedPipelines = append(dst.Status.ExcludedPipelines, ExcludedPipeline(e))
+	}
+	return nil
+}
+
+func convertAgentTo(src *VectorAgent) *v1alpha1.VectorAgent {
+	return &v1alpha1.VectorAgent{
+		Image:                    src.Image,
+		ImagePullSecrets:         src.ImagePullSecrets,
+		ImagePullPolicy:          src.ImagePullPolicy,
+		Resources:                src.Resources,
+		Affinity:                 src.Affinity,
+		Tolerations:              src.Tolerations,
+		SecurityContext:          src.SecurityContext,
+		ContainerSecurityContext: src.ContainerSecurityContext,
+		SchedulerName:            src.SchedulerName,
+		RuntimeClassName:         src.RuntimeClassName,
+		HostAliases:              src.HostAliases,
+		PriorityClassName:        src.PriorityClassName,
+		HostNetwork:              src.HostNetwork,
+		Env:                      src.Env,
+		DataDir:                  src.DataDir,
+		Api:                      v1alpha1.ApiSpec(src.Api),
+		InternalMetrics:          src.InternalMetrics,
+		Volumes:                  src.Volumes,
+		VolumeMounts:             src.VolumeMounts,
+		ConfigCheck:              v1alpha1.ConfigCheck(src.ConfigCheck),
+		CompressConfigFile:       src.CompressConfigFile,
+		ConfigReloaderImage:      src.ConfigReloaderImage,
+		ConfigReloaderResources:  src.ConfigReloaderResources,
+	}
+}
+
+func convertAgentFrom(src *v1alpha1.VectorAgent) *VectorAgent {
+	return &VectorAgent{
+		Image:                    src.Image,
+		ImagePullSecrets:         src.ImagePullSecrets,
+		ImagePullPolicy:          src.ImagePullPolicy,
+		Resources:                src.Resources,
+		Affinity:                 src.Affinity,
+		Tolerations:              src.Tolerations,
+		SecurityContext:          src.SecurityContext,
+		ContainerSecurityContext: src.ContainerSecurityContext,
+		SchedulerName:            src.SchedulerName,
+		RuntimeClassName:         src.RuntimeClassName,
+		HostAliases:              src.HostAliases,
+		PriorityClassName:        src.PriorityClassName,
+		HostNetwork:              src.HostNetwork,
+		Env:                      src.Env,
+		DataDir:                  src.DataDir,
+		Api:                      ApiSpec(src.Api),
+		InternalMetrics:          src.InternalMetrics,
+		Volumes:                  src.Volumes,
+		VolumeMounts:             src.VolumeMounts,
+		ConfigCheck:              ConfigCheck(src.ConfigCheck),
+		CompressConfigFile:       src.CompressConfigFile,
+		ConfigReloaderImage:      src.ConfigReloaderImage,
+		ConfigReloaderResources:  src.ConfigReloaderResources,
+	}
+}
+
+func convertAggregatorTo(src *VectorAggregator) *v1alpha1.VectorAggregator {
+	return &v1alpha1.VectorAggregator{
+		Enable:                   src.Enable,
+		Image:                    src.Image,
+		Replicas:                 src.Replicas,
+		ImagePullSecrets:         src.ImagePullSecrets,
+		ImagePullPolicy:          src.ImagePullPolicy,
+		Resources:                src.Resources,
+		Affinity:                 src.Affinity,
+		Tolerations:              src.Tolerations,
+		SecurityContext:          src.SecurityContext,
+		ContainerSecurityContext: src.ContainerSecurityContext,
+		PriorityClassName:        src.PriorityClassName,
+		Env:                      src.Env,
+		Api:                      v1alpha1.ApiSpec(src.Api),
+		InternalMetrics:          src.InternalMetrics,
+		Volumes:                  src.Volumes,
+		VolumeMounts:             src.VolumeMounts,
+		DataVolumeClaimTemplate:  src.DataVolumeClaimTemplate,
+	}
+}
+
+func convertAggregatorFrom(src *v1alpha1.VectorAggregator) *VectorAggregator {
+	return &VectorAggregator{
+		Enable:                   src.Enable,
+		Image:                    src.Image,
+		Replicas:                 src.Replicas,
+		ImagePullSecrets:         src.ImagePullSecrets,
+		ImagePullPolicy:          src.ImagePullPolicy,
+		Resources:                src.Resources,
+		Affinity:                 src.Affinity,
+		Tolerations:              src.Tolerations,
+		SecurityContext:          src.SecurityContext,
+		ContainerSecurityContext: src.ContainerSecurityContext,
+		PriorityClassName:        src.PriorityClassName,
+		Env:                      src.Env,
+		Api:                      ApiSpec(src.Api),
+		InternalMetrics:          src.InternalMetrics,
+		Volumes:                  src.Volumes,
+		VolumeMounts:             src.VolumeMounts,
+		DataVolumeClaimTemplate:  src.DataVolumeClaimTemplate,
+	}
+}
+
+// withAnnotation returns copy of annotations with key set, source object metadata is not changed
+func withAnnotation(annotations map[string]string, key, value string) map[string]string {
+	result := make(map[string]string, len(annotations)+1)
+	for k, v := range annotations {
+		result[k] = v
+	}
+	result[key] = value
+	return result
+}
+
+// withoutAnnotation returns copy of annotations without key, nil if no annotations are left
+func withoutAnnotation(annotations map[string]string, key string) map[string]string {
+	var result map[string]string
+	for k, v := range annotations {
+		if k == key {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string, len(annotations))
+		}
+		result[k] = v
+	}
+	return result
+}
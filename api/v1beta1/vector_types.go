@@ -0,0 +1,336 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VectorSpec defines the desired state of Vector
+type VectorSpec struct {
+	// Vector Agent
+	Agent *VectorAgent `json:"agent,omitempty"`
+
+	// Merge kubernetes sources and move selectors processing to transforms.
+	// +optional
+	MergeKubernetesSources bool `json:"mergeKubernetesSources,omitempty"`
+	// Merge kubernetes sink with equal options.
+	// +optional
+	MergeSinks bool `json:"mergeSinks,omitempty"`
+
+	// Vector Aggregator
+	// +optional
+	Aggregator *VectorAggregator `json:"aggregator,omitempty"`
+
+	// PipelineSelector selects VectorPipelines and ClusterVectorPipelines by labels.
+	// If not specified - all pipelines are selected
+	// +optional
+	PipelineSelector *metav1.LabelSelector `json:"pipelineSelector,omitempty"`
+	// PipelineNamespaceSelector selects VectorPipelines by labels of their namespace.
+	// Not applied to ClusterVectorPipelines. If not specified - pipelines from all namespaces are selected
+	// +optional
+	PipelineNamespaceSelector *metav1.LabelSelector `json:"pipelineNamespaceSelector,omitempty"`
+
+	// EnvAllowlist restricts env variables, that VectorPipelines can interpolate in options with ${VAR} or $VAR.
+	// Pipeline can use variable, if any rule matches pipeline namespace and variable name.
+	// VECTOR_SELF_* variables are always allowed. ClusterVectorPipelines are not restricted.
+	// If not specified - VectorPipelines can use all env variables
+	// +optional
+	EnvAllowlist []EnvAllowlistRule `json:"envAllowlist,omitempty"`
+
+	// DryRun renders Vector Agent and Vector Aggregator configs into <name>-rendered-config Secret
+	// without config check and deploy. Already deployed Vector keeps working with last applied config
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// EnvAllowlistRule allows VectorPipelines in namespaces to use env variables
+type EnvAllowlistRule struct {
+	// Namespaces are names of VectorPipelines namespaces. If not specified - all namespaces
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Env are names of env variables. Shell patterns are supported, like ELASTIC_*
+	Env []string `json:"env"`
+}
+
+// VectorStatus defines the observed state of Vector
+type VectorStatus struct {
+	// ConfigCheckResult is result of the last config check
+	// +optional
+	ConfigCheckResult *bool `json:"configCheckResult,omitempty"`
+	// Reason is output of the last failed config check
+	// +optional
+	Reason *string `json:"reason,omitempty"`
+	// LastAppliedConfigHash is hash of the last applied Vector Agent config
+	// +optional
+	LastAppliedConfigHash *uint32 `json:"lastAppliedConfigHash,omitempty"`
+	// LastAppliedAggregatorConfigHash is hash of the last applied Vector Aggregator config
+	LastAppliedAggregatorConfigHash *uint32 `json:"lastAppliedAggregatorConfigHash,omitempty"`
+	// Agent is Vector Agent DaemonSet rollout status
+	// +optional
+	Agent *VectorAgentStatus `json:"agent,omitempty"`
+	// ObservedGeneration is the last Vector generation processed by operator
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions represent the latest observations of Vector state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// ConfigErrors are errors of the last failed config check, parsed from vector validate output
+	// +optional
+	ConfigErrors []ConfigError `json:"configErrors,omitempty"`
+	// ExcludedPipelines are pipelines, that fail config build or config check of this Vector. They are excluded
+	// from configs of this Vector until their spec is changed, other Vectors keep them
+	// +optional
+	ExcludedPipelines []ExcludedPipeline `json:"excludedPipelines,omitempty"`
+}
+
+// ExcludedPipeline is pipeline, that is excluded from Vector configs
+type ExcludedPipeline struct {
+	// Pipeline is namespace/name of pipeline. Name is used for ClusterVectorPipeline
+	Pipeline string `json:"pipeline"`
+	// PipelineHash is hash of pipeline spec, that failed. Pipeline is tried again, when its spec is changed
+	PipelineHash uint32 `json:"pipelineHash"`
+	// Reason is machine-readable reason of exclusion
+	Reason string `json:"reason"`
+	// Message is error of config build or config check
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ConfigError is Vector config error, reported by vector validate
+type ConfigError struct {
+	// Pipeline is namespace/name of pipeline, that defines component. Name is used for ClusterVectorPipeline.
+	// Empty for components added by operator and components merged from several pipelines
+	// +optional
+	Pipeline string `json:"pipeline,omitempty"`
+	// Component is component name as it is defined in pipeline
+	// +optional
+	Component string `json:"component,omitempty"`
+	// Kind is error kind: Component, Input, Duplicate, DataType, HealthCheck, Config or Unknown
+	Kind string `json:"kind"`
+	// Message is the first line of error message
+	Message string `json:"message"`
+}
+
+// VectorAgentStatus mirrors Vector Agent DaemonSet pods counts
+type VectorAgentStatus struct {
+	// DesiredNumberScheduled is the number of nodes that should be running Vector Agent pod
+	DesiredNumberScheduled int32 `json:"desiredNumberScheduled"`
+	// NumberReady is the number of nodes with ready Vector Agent pod
+	NumberReady int32 `json:"numberReady"`
+	// UpdatedNumberScheduled is the number of nodes that are running updated Vector Agent pod
+	UpdatedNumberScheduled int32 `json:"updatedNumberScheduled"`
+	// NumberUnavailable is the number of nodes without available Vector Agent pod
+	NumberUnavailable int32 `json:"numberUnavailable"`
+}
+
+// VectorAgent is the Schema for the Vector Agent
+type VectorAgent struct {
+	// Image - docker image settings for Vector Agent
+	// if no specified operator uses default config version
+	// +optional
+	Image string `json:"image,omitempty"`
+	// ImagePullSecrets An optional list of references to secrets in the same namespace
+	// to use for pulling images from registries
+	// see http://kubernetes.io/docs/user-guide/images#specifying-imagepullsecrets-on-a-pod
+	// +optional
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// ImagePullPolicy of pods
+	ImagePullPolicy v1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Resources container resource request and limits, https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
+	// if not specified - default setting will be used
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resources",xDescriptors="urn:alm:descriptor:com.tectonic.ui:resourceRequirements"
+	// +optional
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	// Affinity If specified, the pod's scheduling constraints.
+	// +optional
+	Affinity *v1.Affinity `json:"affinity,omitempty"`
+	// Tolerations If specified, the pod's tolerations.
+	// +optional
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// SecurityContext holds pod-level security attributes and common container settings.
+	// This defaults to the default PodSecurityContext.
+	// +optional
+	SecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// SecurityContext holds security configuration that will be applied to a container.
+	// Some fields are present in both SecurityContext and PodSecurityContext.
+	// When both are set, the values in SecurityContext take precedence.
+	// +optional
+	ContainerSecurityContext *v1.SecurityContext `json:"containerSecurityContext,omitempty"`
+	// SchedulerName - defines kubernetes scheduler name
+	// +optional
+	SchedulerName string `json:"schedulerName,omitempty"`
+	// RuntimeClassName - defines runtime class for kubernetes pod.
+	// https://kubernetes.io/docs/concepts/containers/runtime-class/
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+	// HostAliases provides mapping between ip and hostnames,
+	// that would be propagated to pod,
+	// cannot be used with HostNetwork.
+	// +optional
+	HostAliases []v1.HostAlias `json:"hostAliases,omitempty"`
+	// PriorityClassName assigned to the Pods
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// HostNetwork controls whether the pod may use the node network namespace
+	// +optional
+	HostNetwork bool `json:"hostNetwork,omitempty"`
+	// Env that will be added to Vector pod
+	Env []v1.EnvVar `json:"env,omitempty"`
+
+	DataDir string  `json:"dataDir,omitempty"`
+	Api     ApiSpec `json:"api,omitempty"`
+
+	// Enable internal metrics exporter
+	// +optional
+	InternalMetrics bool `json:"internalMetrics,omitempty"`
+
+	// List of volumes that can be mounted by containers belonging to the pod.
+	// +optional
+	Volumes []v1.Volume `json:"volumes,omitempty"`
+
+	// Pod volumes to mount into the container's filesystem.
+	// +optional
+	VolumeMounts []v1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	ConfigCheck ConfigCheck `json:"configCheck,omitempty"`
+	// Compress config file
+	CompressConfigFile      bool                    `json:"compressConfigFile,omitempty"`
+	ConfigReloaderImage     string                  `json:"configReloaderImage,omitempty"`
+	ConfigReloaderResources v1.ResourceRequirements `json:"configReloaderResources,omitempty"`
+}
+
+// ApiSpec is the Schema for the Vector Agent GraphQL API - https://vector.dev/docs/reference/api/
+type ApiSpec struct {
+	Enabled    bool `json:"enabled,omitempty"`
+	Playground bool `json:"playground,omitempty"`
+}
+
+// ConfigCheck is the Schema for control params for ConfigCheck pods
+type ConfigCheck struct {
+	// Image - docker image settings for Vector Agent
+	// if no specified operator uses default config version
+	// +optional
+	Image *string `json:"image,omitempty"`
+	// Resources container resource request and limits, https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
+	// if not specified - default setting will be used
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resources",xDescriptors="urn:alm:descriptor:com.tectonic.ui:resourceRequirements"
+	// +optional
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+	// Affinity If specified, the pod's scheduling constraints.
+	// +optional
+	Affinity *v1.Affinity `json:"affinity,omitempty"`
+	// Tolerations If specified, the pod's tolerations.
+	// +optional
+	Tolerations *[]v1.Toleration `json:"tolerations,omitempty"`
+}
+
+// VectorAggregator is the Schema for the Vector Aggregator
+type VectorAggregator struct {
+	// Enable deploys Vector Aggregator StatefulSet
+	Enable bool `json:"enable,omitempty"`
+	// Image - docker image settings for Vector Aggregator
+	// if no specified operator uses default config version
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Replicas - number of Vector Aggregator pods. 1 by default
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// ImagePullSecrets An optional list of references to secrets in the same namespace
+	// to use for pulling images from registries
+	// see http://kubernetes.io/docs/user-guide/images#specifying-imagepullsecrets-on-a-pod
+	// +optional
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// ImagePullPolicy of pods
+	ImagePullPolicy v1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Resources container resource request and limits, https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
+	// if not specified - default setting will be used
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resources",xDescriptors="urn:alm:descriptor:com.tectonic.ui:resourceRequirements"
+	// +optional
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	// Affinity If specified, the pod's scheduling constraints.
+	// +optional
+	Affinity *v1.Affinity `json:"affinity,omitempty"`
+	// Tolerations If specified, the pod's tolerations.
+	// +optional
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// SecurityContext holds pod-level security attributes and common container settings.
+	// This defaults to the default PodSecurityContext.
+	// +optional
+	SecurityContext *v1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// SecurityContext holds security configuration that will be applied to a container.
+	// Some fields are present in both SecurityContext and PodSecurityContext.
+	// When both are set, the values in SecurityContext take precedence.
+	// +optional
+	ContainerSecurityContext *v1.SecurityContext `json:"containerSecurityContext,omitempty"`
+	// PriorityClassName assigned to the Pods
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// Env that will be added to Vector pod
+	Env []v1.EnvVar `json:"env,omitempty"`
+
+	Api ApiSpec `json:"api,omitempty"`
+
+	// Enable internal metrics exporter
+	// +optional
+	InternalMetrics bool `json:"internalMetrics,omitempty"`
+
+	// List of volumes that can be mounted by containers belonging to the pod.
+	// +optional
+	Volumes []v1.Volume `json:"volumes,omitempty"`
+
+	// Pod volumes to mount into the container's filesystem.
+	// +optional
+	VolumeMounts []v1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// DataVolumeClaimTemplate - spec of PersistentVolumeClaim for Vector data dir of every Aggregator pod,
+	// so disk buffers are kept on pod restart. If not specified, emptyDir is used
+	// +optional
+	DataVolumeClaimTemplate *v1.PersistentVolumeClaimSpec `json:"dataVolumeClaimTemplate,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+//+kubebuilder:printcolumn:name="Valid",type="boolean",JSONPath=".status.configCheckResult"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+
+// Vector is the Schema for the vectors API
+type Vector struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VectorSpec   `json:"spec,omitempty"`
+	Status VectorStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// VectorList contains a list of Vector
+type VectorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Vector `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Vector{}, &VectorList{})
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/kaasops/vector-operator/api/v1alpha1"
+)
+
+// ConvertTo converts VectorPipeline to hub version v1alpha1
+func (src *VectorPipeline) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.VectorPipeline)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Status = convertPipelineStatusTo(src.Status)
+	return convertPipelineSpecTo(&src.Spec, &dst.Spec)
+}
+
+// ConvertFrom converts VectorPipeline from hub version v1alpha1
+func (dst *VectorPipeline) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.VectorPipeline)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Status = convertPipelineStatusFrom(src.Status)
+	return convertPipelineSpecFrom(&src.Spec, &dst.Spec)
+}
+
+// ConvertTo converts ClusterVectorPipeline to hub version v1alpha1
+func (src *ClusterVectorPipeline) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.ClusterVectorPipeline)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Status = convertPipelineStatusTo(src.Status)
+	return convertPipelineSpecTo(&src.Spec, &dst.Spec)
+}
+
+// ConvertFrom converts ClusterVectorPipeline from hub version v1alpha1
+func (dst *ClusterVectorPipeline) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.ClusterVectorPipeline)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Status = convertPipelineStatusFrom(src.Status)
+	return convertPipelineSpecFrom(&src.Spec, &dst.Spec)
+}
+
+func convertPipelineSpecTo(src *VectorPipelineSpec, dst *v1alpha1.VectorPipelineSpec) error {
+	dst.Role = src.Role
+	dst.DryRun = src.DryRun
+	dst.Sources = src.Sources
+	dst.Transforms = src.Transforms
+	dst.Sinks = src.Sinks
+	return convertTypedComponents(src, dst)
+}
+
+func convertPipelineSpecFrom(src *v1alpha1.VectorPipelineSpec, dst *VectorPipelineSpec) error {
+	dst.Role = src.Role
+	dst.DryRun = src.DryRun
+	dst.Sources = src.Sources
+	dst.Transforms = src.Transforms
+	dst.Sinks = src.Sinks
+	return convertTypedComponents(src, dst)
+}
+
+// convertTypedComponents copies typed components, that have the same JSON in both versions. Types are kept
+// identical, TestTypedComponentsRoundTrip fails, if they drift
+func convertTypedComponents(src, dst interface{}) error {
+	var typed struct {
+		TypedSources    json.RawMessage `json:"typedSources,omitempty"`
+		TypedTransforms json.RawMessage `json:"typedTransforms,omitempty"`
+		TypedSinks      json.RawMessage `json:"typedSinks,omitempty"`
+	}
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return err
+	}
+	data, err = json.Marshal(typed)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+func convertPipelineStatusTo(src VectorPipelineStatus) v1alpha1.VectorPipelineStatus {
+	dst := v1alpha1.VectorPipelineStatus{
+		ConfigCheckResult:       src.ConfigCheckResult,
+		Reason:                  src.Reason,
+		LastAppliedPipelineHash: src.LastAppliedPipelineHash,
+		ObservedGeneration:      src.ObservedGeneration,
+		Conditions:              src.Conditions,
+		AppliedSpec:             src.AppliedSpec,
+	}
+	for _, c := range src.RenderedConfigs {
+		dst.RenderedConfigs = append(dst.RenderedConfigs, v1alpha1.RenderedConfig(c))
+	}
+	for _, e := range src.ConfigErrors {
+		dst.ConfigErrors = append(dst.ConfigErrors, v1alpha1.ConfigError(e))
+	}
+	return dst
+}
+
+func convertPipelineStatusFrom(src v1alpha1.VectorPipelineStatus) VectorPipelineStatus {
+	dst := VectorPipelineStatus{
+		ConfigCheckResult:       src.ConfigCheckResult,
+		Reason:                  src.Reason,
+		LastAppliedPipelineHash: src.LastAppliedPipelineHash,
+		ObservedGeneration:      src.ObservedGeneration,
+		Conditions:              src.Conditions,
+		AppliedSpec:             src.AppliedSpec,
+	}
+	for _, c := range src.RenderedConfigs {
+		dst.RenderedConfigs = append(dst.RenderedConfigs, RenderedConfig(c))
+	}
+	for _, e := range src.ConfigErrors {
+		dst.ConfigErrors = append(dst.ConfigErrors, ConfigError(e))
+	}
+	return dst
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// VectorPipelineSpec defines the desired state of VectorPipeline
+type VectorPipelineSpec struct {
+	// Role defines where pipeline runs: agent or aggregator. agent by default.
+	// Sources of aggregator pipelines are collected by agents and forwarded to Vector Aggregator.
+	// +kubebuilder:validation:Enum=agent;aggregator
+	// +optional
+	Role string `json:"role,omitempty"`
+	// DryRun renders config of selected Vectors with this pipeline into status.renderedConfigs.
+	// Spec is not checked and not applied in dry run mode, Vectors keep the last checked spec of pipeline.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Sources *runtime.RawExtension `json:"sources,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Transforms *runtime.RawExtension `json:"transforms,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Sinks *runtime.RawExtension `json:"sinks,omitempty"`
+	// TypedSources are sources with validated options. Names must not clash with sources
+	// +optional
+	TypedSources map[string]SourceSpec `json:"typedSources,omitempty"`
+	// TypedTransforms are transforms with validated options. Names must not clash with transforms
+	// +optional
+	TypedTransforms map[string]TransformSpec `json:"typedTransforms,omitempty"`
+	// TypedSinks are sinks with validated options. Names must not clash with sinks
+	// +optional
+	TypedSinks map[string]SinkSpec `json:"typedSinks,omitempty"`
+}
+
+// VectorPipelineStatus defines the observed state of VectorPipeline
+type VectorPipelineStatus struct {
+	// ConfigCheckResult is result of the last config check
+	// +optional
+	ConfigCheckResult *bool `json:"configCheckResult,omitempty"`
+	// Reason is output of the last failed config check
+	// +optional
+	Reason *string `json:"reason,omitempty"`
+	// LastAppliedPipelineHash is hash of the last checked pipeline spec
+	// +optional
+	LastAppliedPipelineHash *uint32 `json:"lastAppliedPipelineHash,omitempty"`
+	// ObservedGeneration is the last pipeline generation processed by operator
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions represent the latest observations of pipeline state
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// RenderedConfigs contains Vector configs with pipeline, rendered in dry run mode
+	// +optional
+	RenderedConfigs []RenderedConfig `json:"renderedConfigs,omitempty"`
+	// ConfigErrors are errors of the last failed config check, parsed from vector validate output
+	// +optional
+	ConfigErrors []ConfigError `json:"configErrors,omitempty"`
+	// AppliedSpec is pipeline spec, that passed config check the last time, in v1alpha1 format. Vectors keep
+	// applying it, while pipeline is in dry run mode
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	AppliedSpec *runtime.RawExtension `json:"appliedSpec,omitempty"`
+}
+
+// RenderedConfig is Vector config with single pipeline, as it is passed to config check
+type RenderedConfig struct {
+	// Vector is namespace/name of Vector, that selects pipeline
+	Vector string `json:"vector"`
+	// Agent is Vector Agent config
+	Agent string `json:"agent,omitempty"`
+	// Aggregator is Vector Aggregator config. Rendered for aggregator pipelines only
+	// +optional
+	Aggregator string `json:"aggregator,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=vp,categories=all
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+//+kubebuilder:printcolumn:name="Valid",type="boolean",JSONPath=".status.configCheckResult"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+
+// VectorPipeline is the Schema for the vectorpipelines API
+type VectorPipeline struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VectorPipelineSpec   `json:"spec,omitempty"`
+	Status VectorPipelineStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// VectorPipelineList contains a list of VectorPipeline
+type VectorPipelineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VectorPipeline `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&VectorPipeline{}, &VectorPipelineList{})
+}
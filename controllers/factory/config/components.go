@@ -0,0 +1,92 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// inputsKey is the only key of typed component, that is not a component type
+const inputsKey = "inputs"
+
+// decodeComponents returns raw and typed components by name. Typed components are converted to the same
+// options as raw components, so they are decoded the same way
+func decodeComponents(raw *runtime.RawExtension, typed interface{}) (map[string]interface{}, error) {
+	components := make(map[string]interface{})
+	if raw != nil {
+		rawComponents, err := decodeRaw(raw.Raw)
+		if err != nil {
+			return nil, err
+		}
+		for name, component := range rawComponents {
+			components[name] = component
+		}
+	}
+	typedComponents, err := decodeTyped(typed)
+	if err != nil {
+		return nil, err
+	}
+	for name, component := range typedComponents {
+		if _, ok := components[name]; ok {
+			return nil, fmt.Errorf("%w: %s: component with the same name is defined in raw components", PipelineComponentError, name)
+		}
+		components[name] = component
+	}
+	return components, nil
+}
+
+// decodeTyped converts typed components to Vector components: the set component field becomes type
+// and its fields become options
+func decodeTyped(typed interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(typed)
+	if err != nil {
+		return nil, err
+	}
+	components, err := decodeRaw(data)
+	if err != nil {
+		return nil, err
+	}
+	for name, v := range components {
+		spec, _ := v.(map[string]interface{})
+		component := make(map[string]interface{})
+		for key, value := range spec {
+			if key == inputsKey {
+				component[key] = value
+				continue
+			}
+			if t, ok := component["type"]; ok {
+				return nil, fmt.Errorf("%w: %s: only one component type can be set, got %s and %s", PipelineComponentError, name, t, key)
+			}
+			options, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%w: %s: %s must be an object", PipelineComponentError, name, key)
+			}
+			component["type"] = key
+			for option, optionValue := range options {
+				component[option] = optionValue
+			}
+		}
+		if _, ok := component["type"]; !ok {
+			return nil, fmt.Errorf("%w: %s: component type is not set", PipelineComponentError, name)
+		}
+		components[name] = component
+	}
+	return components, nil
+}
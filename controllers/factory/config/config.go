@@ -21,6 +21,7 @@ import (
 
 	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
 	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoragent"
+	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoraggregator"
 	"github.com/mitchellh/mapstructure"
 )
 
@@ -48,6 +49,21 @@ func New(vector *vectorv1alpha1.Vector) *VectorConfig {
 	}
 }
 
+func NewAggregator(vector *vectorv1alpha1.Vector) *VectorConfig {
+	api := &ApiSpec{
+		Address:    "0.0.0.0:" + strconv.Itoa(vectoraggregator.ApiPort),
+		Enabled:    vector.Spec.Aggregator.Api.Enabled,
+		Playground: vector.Spec.Aggregator.Api.Playground,
+	}
+
+	return &VectorConfig{
+		DataDir: "/vector-data-dir",
+		Api:     api,
+		Sources: []*Source{},
+		Sinks:   []*Sink{},
+	}
+}
+
 func Mapper(c ConfigComponent) (map[string]interface{}, error) {
 	spec := c.GetOptions()
 	config := &mapstructure.DecoderConfig{
@@ -22,13 +22,16 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
 	"github.com/kaasops/vector-operator/controllers/factory/pipeline"
 	"github.com/kaasops/vector-operator/controllers/factory/utils/hash"
 	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	"github.com/kaasops/vector-operator/controllers/factory/valueref"
 	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoragent"
+	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoraggregator"
 	"github.com/mitchellh/mapstructure"
 )
 
@@ -46,6 +49,18 @@ const (
 	PodSelectorType            = "pod_labels"
 	NamespaceSelectorType      = "ns_labels"
 	OptimizationConditionType  = "vrl"
+	RemapTransformType         = "remap"
+)
+
+const (
+	VectorSourceType                 = "vector"
+	VectorSinkType                   = "vector"
+	AggregatorSourceName             = "aggregatorSource"
+	AggregatorSinkName               = "aggregatorSink"
+	AggregatorRouteTransformName     = "aggregatorRoute"
+	AggregatorForwardTransformPrefix = "aggregatorForward-"
+	// ForwardedSourceField marks events forwarded to aggregator with origin pipeline source name
+	ForwardedSourceField = "_vector_operator_source"
 )
 
 var (
@@ -74,25 +89,140 @@ var (
 )
 
 var (
-	PipelineTypeError  error = errors.New("type kubernetes_logs only allowed")
-	PipelineScopeError error = errors.New("logs from external namespace not allowed")
+	PipelineTypeError       error = errors.New("type kubernetes_logs only allowed")
+	PipelineScopeError      error = errors.New("logs from external namespace not allowed")
+	PipelineAggregatorError error = errors.New("aggregator role not allowed, Vector Aggregator is not enabled")
+	PipelineEnvError        error = errors.New("env variables not allowed")
+	PipelineComponentError  error = errors.New("invalid typed component")
+	PipelineTemplateError   error = errors.New("invalid template transform")
 )
 
+// GetConditionReason returns status condition reason for config build error
+func GetConditionReason(err error) string {
+	switch {
+	case errors.Is(err, PipelineTypeError):
+		return vectorv1alpha1.ReasonSourceTypeNotAllowed
+	case errors.Is(err, PipelineScopeError):
+		return vectorv1alpha1.ReasonNamespaceScopeViolation
+	case errors.Is(err, PipelineAggregatorError):
+		return vectorv1alpha1.ReasonAggregatorNotEnabled
+	case errors.Is(err, PipelineTopologyError):
+		return vectorv1alpha1.ReasonInvalidTopology
+	case errors.Is(err, valueref.ValueRefError):
+		return vectorv1alpha1.ReasonInvalidValueRef
+	case errors.Is(err, PipelineEnvError):
+		return vectorv1alpha1.ReasonEnvNotAllowed
+	case errors.Is(err, PipelineComponentError):
+		return vectorv1alpha1.ReasonInvalidComponent
+	case errors.Is(err, PipelineTemplateError):
+		return vectorv1alpha1.ReasonInvalidTemplate
+	}
+	return vectorv1alpha1.ReasonConfigBuildFailed
+}
+
+// IsPipelineError returns true, if config is not built because of invalid pipeline.
+// Such errors are reported in status and are not retried
+func IsPipelineError(err error) bool {
+	return GetConditionReason(err) != vectorv1alpha1.ReasonConfigBuildFailed
+}
+
+// PipelineBuildError is config build error caused by pipeline
+type PipelineBuildError struct {
+	// Pipeline is namespace/name of pipeline
+	Pipeline string
+	Err      error
+}
+
+func (e *PipelineBuildError) Error() string {
+	return fmt.Sprintf("pipeline %s: %s", e.Pipeline, e.Err)
+}
+
+func (e *PipelineBuildError) Unwrap() error {
+	return e.Err
+}
+
+// GetPipelineErrors returns config build errors by originating pipeline namespace/name. Errors, that are not
+// caused by invalid pipeline, and topology errors of components added by operator are not attributed to pipelines
+func GetPipelineErrors(err error) map[string]error {
+	var buildErr *PipelineBuildError
+	if errors.As(err, &buildErr) {
+		if !IsPipelineError(buildErr.Err) {
+			return nil
+		}
+		return map[string]error{buildErr.Pipeline: buildErr.Err}
+	}
+	var topologyErrs TopologyErrors
+	if !errors.As(err, &topologyErrs) {
+		return nil
+	}
+	pipelineErrors := make(map[string]error)
+	for _, p := range topologyErrs.Pipelines() {
+		var errs TopologyErrors
+		for _, e := range topologyErrs {
+			if e.Pipeline == p {
+				errs = append(errs, e)
+			}
+		}
+		pipelineErrors[p] = errs
+	}
+	return pipelineErrors
+}
+
 type Builder struct {
-	Name      string
-	vaCtrl    *vectoragent.Controller
-	Pipelines []pipeline.Pipeline
+	Name       string
+	vector     *vectorv1alpha1.Vector
+	aggregator bool
+	Pipelines  []pipeline.Pipeline
+	templates  Templates
+	valueRefs  []valueref.Ref
+	// beforeMerge and afterMerge are numbers of components of last built config
+	beforeMerge ComponentsCount
+	afterMerge  ComponentsCount
+}
+
+// ComponentsCount is number of components in Vector config
+type ComponentsCount struct {
+	Sources    int
+	Transforms int
+	Sinks      int
+}
+
+func countComponents(config *VectorConfig) ComponentsCount {
+	return ComponentsCount{
+		Sources:    len(config.Sources),
+		Transforms: len(config.Transforms),
+		Sinks:      len(config.Sinks),
+	}
 }
 
 func NewBuilder(vaCtrl *vectoragent.Controller, pipelines ...pipeline.Pipeline) *Builder {
 	return &Builder{
-		vaCtrl:    vaCtrl,
+		vector:    vaCtrl.Vector,
 		Pipelines: pipelines,
 	}
 }
 
+// NewAggregatorBuilder returns Builder for Vector Aggregator configuration
+func NewAggregatorBuilder(vagCtrl *vectoraggregator.Controller, pipelines ...pipeline.Pipeline) *Builder {
+	return &Builder{
+		vector:     vagCtrl.Vector,
+		aggregator: true,
+		Pipelines:  pipelines,
+	}
+}
+
+// WithTemplates sets VectorTransformTemplates, that are referenced by template transforms of pipelines
+func (b *Builder) WithTemplates(templates Templates) *Builder {
+	b.templates = templates
+	return b
+}
+
 func (b *Builder) GetByteConfig() ([]byte, error) {
-	config, err := b.generateVectorConfig()
+	generate := b.generateVectorConfig
+	if b.aggregator {
+		generate = b.generateAggregatorConfig
+	}
+	config, err := generate()
 	if err != nil {
 		return nil, err
 	}
@@ -103,15 +233,27 @@ func (b *Builder) GetByteConfig() ([]byte, error) {
 	return data, nil
 }
 
+// GetValueRefs returns Secret and ConfigMap keys referenced by components of last built config
+func (b *Builder) GetValueRefs() []valueref.Ref {
+	return b.valueRefs
+}
+
+// GetComponentsCount returns numbers of components of last built config before and after
+// merge of kubernetes sources and sinks
+func (b *Builder) GetComponentsCount() (beforeMerge, afterMerge ComponentsCount) {
+	return b.beforeMerge, b.afterMerge
+}
+
 func (b *Builder) generateVectorConfig() (*VectorConfig, error) {
-	vectorConfig := New(b.vaCtrl.Vector)
+	vectorConfig := New(b.vector)
 
 	sources, transforms, sinks, err := b.getComponents()
 	if err != nil {
 		return nil, err
 	}
+	b.valueRefs = getValueRefs(sources, transforms, sinks)
 
-	if b.vaCtrl.Vector.Spec.Agent.InternalMetrics && !isExporterSinkExists(sinks) {
+	if b.vector.Spec.Agent.InternalMetrics && !isExporterSinkExists(sinks) {
 		sources = append(sources, internalMetricSource)
 		sinks = append(sinks, internalMetricsExporter)
 	}
@@ -127,73 +269,243 @@ func (b *Builder) generateVectorConfig() (*VectorConfig, error) {
 	vectorConfig.Sources = sources
 	vectorConfig.Transforms = transforms
 
-	if b.vaCtrl.Vector.Spec.MergeKubernetesSources {
+	if err := vectorConfig.CheckTopology(); err != nil {
+		return nil, err
+	}
+	b.beforeMerge = countComponents(vectorConfig)
+
+	if b.vector.Spec.MergeKubernetesSources {
 		if err := b.mergeKubernetesSources(vectorConfig); err != nil {
 			return nil, err
 		}
 	}
 
-	if b.vaCtrl.Vector.Spec.MergeSinks {
+	if b.vector.Spec.MergeSinks {
+		if err := b.mergeSyncs(vectorConfig); err != nil {
+			return nil, err
+		}
+	}
+	b.afterMerge = countComponents(vectorConfig)
+
+	return vectorConfig, nil
+}
+
+func (b *Builder) generateAggregatorConfig() (*VectorConfig, error) {
+	vectorConfig := NewAggregator(b.vector)
+
+	sources := []*Source{
+		{
+			Name: AggregatorSourceName,
+			Type: VectorSourceType,
+			Options: map[string]interface{}{
+				"address": "0.0.0.0:" + strconv.Itoa(vectoraggregator.SourcePort),
+				"version": "2",
+			},
+		},
+	}
+
+	forwardedSources, transforms, sinks, err := b.getComponents()
+	if err != nil {
+		return nil, err
+	}
+	// Forwarded sources run on agents
+	b.valueRefs = getValueRefs(nil, transforms, sinks)
+
+	// Events from agents come through one vector source, route them back to pipeline sources
+	if len(forwardedSources) > 0 {
+		routes := make(map[string]string)
+		for _, source := range forwardedSources {
+			routes[source.Name] = fmt.Sprintf(".%s == \"%s\"", ForwardedSourceField, source.Name)
+			transforms = append(transforms, &Transform{
+				Name:     source.Name,
+				Pipeline: source.Pipeline,
+				Type:     RemapTransformType,
+				Inputs:   []string{AggregatorRouteTransformName + "." + source.Name},
+				Options: map[string]interface{}{
+					"source": fmt.Sprintf("del(.%s)", ForwardedSourceField),
+				},
+			})
+		}
+		transforms = append(transforms, &Transform{
+			Name:   AggregatorRouteTransformName,
+			Type:   RouteTransformType,
+			Inputs: []string{AggregatorSourceName},
+			Route:  routes,
+		})
+	}
+
+	if b.vector.Spec.Aggregator.InternalMetrics && !isExporterSinkExists(sinks) {
+		sources = append(sources, internalMetricSource)
+		sinks = append(sinks, internalMetricsExporter)
+	}
+
+	// Vector source is consumed by route of forwarded sources, without aggregator pipelines it is sent to default sink
+	if len(forwardedSources) == 0 {
+		sinks = append(sinks,
+			&Sink{
+				Name:    sinkDefault.Name,
+				Type:    BlackholeSinkType,
+				Inputs:  []string{AggregatorSourceName},
+				Options: sinkDefault.Options,
+			},
+		)
+	}
+
+	vectorConfig.Sources = sources
+	vectorConfig.Transforms = transforms
+	vectorConfig.Sinks = sinks
+
+	if err := vectorConfig.CheckTopology(); err != nil {
+		return nil, err
+	}
+	b.beforeMerge = countComponents(vectorConfig)
+
+	if b.vector.Spec.MergeSinks {
 		if err := b.mergeSyncs(vectorConfig); err != nil {
 			return nil, err
 		}
 	}
+	b.afterMerge = countComponents(vectorConfig)
 
 	return vectorConfig, nil
 }
 
+// getComponents returns components of pipelines for Builder role.
+// For agent sources of aggregator pipelines are forwarded to Vector Aggregator with vector sink.
+// For aggregator sources are returned as is, they are collected by agents.
 func (b *Builder) getComponents() (sources []*Source, transforms []*Transform, sinks []*Sink, err error) {
+	var forwardInputs []string
 	for _, pipeline := range b.Pipelines {
-		pipelineSources, err := getSources(pipeline, nil)
+		role := pipeline.GetSpec().GetRole()
+		if role == vectorv1alpha1.PipelineRoleAggregator && !b.vector.IsAggregatorEnabled() {
+			return nil, nil, nil, &PipelineBuildError{Pipeline: pipelineRef(pipeline.GetNamespace(), pipeline.GetName()), Err: PipelineAggregatorError}
+		}
+		if b.aggregator && role != vectorv1alpha1.PipelineRoleAggregator {
+			continue
+		}
+		pipelineSources, pipelineTransforms, pipelineSinks, err := b.getPipelineComponents(pipeline)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, &PipelineBuildError{Pipeline: pipelineRef(pipeline.GetNamespace(), pipeline.GetName()), Err: err}
 		}
-		for _, source := range pipelineSources {
-			if err != nil {
-				return nil, nil, nil, err
-			}
-			if source.Type == KubernetesSourceType {
-				if pipeline.Type() != vectorv1alpha1.ClusterPipelineKind && source.ExtraNamespaceLabelSelector == "" {
-					source.ExtraNamespaceLabelSelector = k8s.NamespaceNameToLabel(pipeline.GetNamespace())
+		sources = append(sources, pipelineSources...)
+		if !b.aggregator && role == vectorv1alpha1.PipelineRoleAggregator {
+			for _, source := range pipelineSources {
+				transform := &Transform{
+					Name:     AggregatorForwardTransformPrefix + source.Name,
+					Pipeline: source.Pipeline,
+					Type:     RemapTransformType,
+					Inputs:   []string{source.Name},
+					Options: map[string]interface{}{
+						"source": fmt.Sprintf(".%s = \"%s\"", ForwardedSourceField, source.Name),
+					},
 				}
+				transforms = append(transforms, transform)
+				forwardInputs = append(forwardInputs, transform.Name)
 			}
-			if pipeline.Type() != vectorv1alpha1.ClusterPipelineKind {
-				if source.Type != KubernetesSourceType {
-					return nil, nil, nil, PipelineTypeError
-				}
-				if source.Type == InternalMetricsSourceType {
-					return nil, nil, nil, PipelineTypeError
-				}
-				if source.ExtraNamespaceLabelSelector != "" {
-					if source.ExtraNamespaceLabelSelector != k8s.NamespaceNameToLabel(pipeline.GetNamespace()) {
-						return nil, nil, nil, PipelineScopeError
-					}
-				}
-			}
-			sources = append(sources, source)
-		}
-		pipelineTransforms, err := getTransforms(pipeline)
-		if err != nil {
-			return nil, nil, nil, err
+			continue
 		}
-		for _, transform := range pipelineTransforms {
-			if err != nil {
-				return nil, nil, nil, err
+		transforms = append(transforms, pipelineTransforms...)
+		sinks = append(sinks, pipelineSinks...)
+	}
+	if len(forwardInputs) > 0 {
+		sinks = append(sinks, &Sink{
+			Name:   AggregatorSinkName,
+			Type:   VectorSinkType,
+			Inputs: forwardInputs,
+			Options: map[string]interface{}{
+				"address": vectoraggregator.ServiceAddress(b.vector),
+				"version": "2",
+				"healthcheck": map[string]interface{}{
+					"enabled": false,
+				},
+			},
+		})
+	}
+	return sources, transforms, sinks, nil
+}
+
+// getPipelineComponents returns components of pipeline. Transforms and sinks of aggregator pipelines are not
+// returned for agent, they run on Vector Aggregator
+func (b *Builder) getPipelineComponents(p pipeline.Pipeline) (sources []*Source, transforms []*Transform, sinks []*Sink, err error) {
+	if err := checkEnvAccess(p, b.vector); err != nil {
+		return nil, nil, nil, err
+	}
+	if sources, err = getPipelineSources(p); err != nil {
+		return nil, nil, nil, err
+	}
+	if !b.aggregator && p.GetSpec().GetRole() == vectorv1alpha1.PipelineRoleAggregator {
+		return sources, nil, nil, nil
+	}
+	if transforms, err = getTransforms(p, b.getTemplates()); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := checkVRLEnvAccess(p, b.vector, transforms); err != nil {
+		return nil, nil, nil, err
+	}
+	if sinks, err = getSinks(p); err != nil {
+		return nil, nil, nil, err
+	}
+	return sources, transforms, sinks, nil
+}
+
+// getTemplates returns templates for expansion, template transforms are not found, if templates are not set
+func (b *Builder) getTemplates() Templates {
+	if b.templates == nil {
+		return Templates{}
+	}
+	return b.templates
+}
+
+func getValueRefs(sources []*Source, transforms []*Transform, sinks []*Sink) []valueref.Ref {
+	var refs []valueref.Ref
+	for _, s := range sources {
+		refs = append(refs, s.ValueRefs...)
+	}
+	for _, t := range transforms {
+		refs = append(refs, t.ValueRefs...)
+	}
+	for _, s := range sinks {
+		refs = append(refs, s.ValueRefs...)
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	return valueref.Unique(refs)
+}
+
+func getPipelineSources(pipeline pipeline.Pipeline) ([]*Source, error) {
+	pipelineSources, err := getSources(pipeline, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, source := range pipelineSources {
+		if source.Type == KubernetesSourceType {
+			if pipeline.Type() != vectorv1alpha1.ClusterPipelineKind && source.ExtraNamespaceLabelSelector == "" {
+				source.ExtraNamespaceLabelSelector = k8s.NamespaceNameToLabel(pipeline.GetNamespace())
 			}
-			transforms = append(transforms, transform)
 		}
-		pipelineSinks, err := getSinks(pipeline)
-		if err != nil {
-			return nil, nil, nil, err
+		if err := checkSourceScope(pipeline, source); err != nil {
+			return nil, err
 		}
-		for _, sink := range pipelineSinks {
-			if err != nil {
-				return nil, nil, nil, err
-			}
-			sinks = append(sinks, sink)
+	}
+	return pipelineSources, nil
+}
+
+// checkSourceScope checks namespace restrictions for sources of VectorPipeline.
+// ClusterVectorPipeline sources are not restricted
+func checkSourceScope(pipeline pipeline.Pipeline, source *Source) error {
+	if pipeline.Type() == vectorv1alpha1.ClusterPipelineKind {
+		return nil
+	}
+	if source.Type != KubernetesSourceType {
+		return PipelineTypeError
+	}
+	if source.ExtraNamespaceLabelSelector != "" {
+		if source.ExtraNamespaceLabelSelector != k8s.NamespaceNameToLabel(pipeline.GetNamespace()) {
+			return PipelineScopeError
 		}
 	}
-	return sources, transforms, sinks, nil
+	return nil
 }
 
 func vectorConfigToByte(config *VectorConfig) ([]byte, error) {
@@ -209,14 +521,14 @@ func vectorConfigToByte(config *VectorConfig) ([]byte, error) {
 }
 
 func getSources(pipeline pipeline.Pipeline, filter []string) ([]*Source, error) {
-	if pipeline.GetSpec().Sources == nil {
-		return nil, nil
-	}
 	var sources []*Source
-	sourcesMap, err := decodeRaw(pipeline.GetSpec().Sources.Raw)
+	sourcesMap, err := decodeComponents(pipeline.GetSpec().Sources, pipeline.GetSpec().TypedSources)
 	if err != nil {
 		return nil, err
 	}
+	if err := valueref.CheckReserved(sourcesMap); err != nil {
+		return nil, err
+	}
 	for k, v := range sourcesMap {
 		if len(filter) != 0 {
 			if !contains(filter, k) {
@@ -228,45 +540,57 @@ func getSources(pipeline pipeline.Pipeline, filter []string) ([]*Source, error)
 			return nil, err
 		}
 		source.Name = addPrefix(pipeline.GetNamespace(), pipeline.GetName(), k)
+		source.Pipeline = pipelineRef(pipeline.GetNamespace(), pipeline.GetName())
+		if source.ValueRefs, err = valueref.Replace(source.Options, pipeline.GetNamespace()); err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
 		sources = append(sources, source)
 	}
 	return sources, nil
 }
 
-func getTransforms(pipeline pipeline.Pipeline) ([]*Transform, error) {
-	if pipeline.GetSpec().Transforms == nil {
-		return nil, nil
-	}
-	transformsMap, err := decodeRaw(pipeline.GetSpec().Transforms.Raw)
+// getTransforms returns transforms of pipeline with expanded template transforms. If templates are nil, template
+// transforms are returned as is: admission webhook checks pipeline topology without templates
+func getTransforms(pipeline pipeline.Pipeline, templates Templates) ([]*Transform, error) {
+	transformsMap, err := decodeComponents(pipeline.GetSpec().Transforms, pipeline.GetSpec().TypedTransforms)
 	if err != nil {
 		return nil, err
 	}
-	var transforms []*Transform
-	if err := json.Unmarshal(pipeline.GetSpec().Transforms.Raw, &transformsMap); err != nil {
+	if templates != nil {
+		if transformsMap, err = expandTemplates(transformsMap, templates); err != nil {
+			return nil, err
+		}
+	}
+	if err := valueref.CheckReserved(transformsMap); err != nil {
 		return nil, err
 	}
+	var transforms []*Transform
 	for k, v := range transformsMap {
 		var transform *Transform
 		if err := mapstructure.Decode(v, &transform); err != nil {
 			return nil, err
 		}
 		transform.Name = addPrefix(pipeline.GetNamespace(), pipeline.GetName(), k)
+		transform.Pipeline = pipelineRef(pipeline.GetNamespace(), pipeline.GetName())
 		for i, inputName := range transform.Inputs {
 			transform.Inputs[i] = addPrefix(pipeline.GetNamespace(), pipeline.GetName(), inputName)
 		}
+		if transform.ValueRefs, err = valueref.Replace(transform.Options, pipeline.GetNamespace()); err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
 		transforms = append(transforms, transform)
 	}
 	return transforms, nil
 }
 
 func getSinks(pipeline pipeline.Pipeline) ([]*Sink, error) {
-	if pipeline.GetSpec().Sinks == nil {
-		return nil, nil
-	}
-	sinksMap, err := decodeRaw(pipeline.GetSpec().Sinks.Raw)
+	sinksMap, err := decodeComponents(pipeline.GetSpec().Sinks, pipeline.GetSpec().TypedSinks)
 	if err != nil {
 		return nil, err
 	}
+	if err := valueref.CheckReserved(sinksMap); err != nil {
+		return nil, err
+	}
 	var sinks []*Sink
 	for k, v := range sinksMap {
 		var sink *Sink
@@ -274,9 +598,13 @@ func getSinks(pipeline pipeline.Pipeline) ([]*Sink, error) {
 			return nil, err
 		}
 		sink.Name = addPrefix(pipeline.GetNamespace(), pipeline.GetName(), k)
+		sink.Pipeline = pipelineRef(pipeline.GetNamespace(), pipeline.GetName())
 		for i, inputName := range sink.Inputs {
 			sink.Inputs[i] = addPrefix(pipeline.GetNamespace(), pipeline.GetName(), inputName)
 		}
+		if sink.ValueRefs, err = valueref.Replace(sink.Options, pipeline.GetNamespace()); err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
 		optbyte, err := json.Marshal(sink.Options)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,549 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/config"
+	"github.com/kaasops/vector-operator/controllers/factory/config/configcheck"
+	"github.com/kaasops/vector-operator/controllers/factory/pipeline"
+	"github.com/kaasops/vector-operator/controllers/factory/valueref"
+	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoragent"
+	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoraggregator"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newTestVector(aggregator bool) *vectorv1alpha1.Vector {
+	return &vectorv1alpha1.Vector{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vector",
+			Namespace: "vector",
+		},
+		Spec: vectorv1alpha1.VectorSpec{
+			Agent: &vectorv1alpha1.VectorAgent{},
+			Aggregator: &vectorv1alpha1.VectorAggregator{
+				Enable: aggregator,
+			},
+		},
+	}
+}
+
+func newTestAgentController() *vectoragent.Controller {
+	return vectoragent.NewController(newTestVector(false), nil, nil)
+}
+
+func newTestPipeline(name, role string) *vectorv1alpha1.VectorPipeline {
+	return &vectorv1alpha1.VectorPipeline{
+		TypeMeta: metav1.TypeMeta{
+			Kind: vectorv1alpha1.LocalPipelineKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "test",
+		},
+		Spec: vectorv1alpha1.VectorPipelineSpec{
+			Role: role,
+			Sources: &runtime.RawExtension{
+				Raw: []byte(`{"source1":{"type":"kubernetes_logs"}}`),
+			},
+			Sinks: &runtime.RawExtension{
+				Raw: []byte(`{"sink1":{"type":"console","inputs":["source1"],"encoding":{"codec":"json"}}}`),
+			},
+		},
+	}
+}
+
+func getComponentNames(t *testing.T, data []byte) map[string][]string {
+	t.Helper()
+	cfg := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(data, &cfg))
+
+	names := make(map[string][]string)
+	for _, key := range []string{"sources", "transforms", "sinks"} {
+		components, _ := cfg[key].(map[string]interface{})
+		for name := range components {
+			names[key] = append(names[key], name)
+		}
+		sort.Strings(names[key])
+	}
+	return names
+}
+
+func TestBuilderPipelineRole(t *testing.T) {
+	type testCase struct {
+		name            string
+		aggregator      bool
+		internalMetrics bool
+		pipelines       []pipeline.Pipeline
+		agent           map[string][]string
+		aggr            map[string][]string
+	}
+
+	testCases := []testCase{
+		{
+			name:       "Agent pipeline",
+			aggregator: true,
+			pipelines:  []pipeline.Pipeline{newTestPipeline("p1", "")},
+			agent: map[string][]string{
+				"sources": {"test-p1-source1"},
+				"sinks":   {"test-p1-sink1"},
+			},
+			aggr: map[string][]string{
+				"sources": {config.AggregatorSourceName},
+				"sinks":   {"defaultSink"},
+			},
+		},
+		{
+			name:       "Aggregator pipeline",
+			aggregator: true,
+			pipelines:  []pipeline.Pipeline{newTestPipeline("p1", vectorv1alpha1.PipelineRoleAggregator)},
+			agent: map[string][]string{
+				"sources":    {"test-p1-source1"},
+				"transforms": {config.AggregatorForwardTransformPrefix + "test-p1-source1"},
+				"sinks":      {config.AggregatorSinkName},
+			},
+			aggr: map[string][]string{
+				"sources":    {config.AggregatorSourceName},
+				"transforms": {config.AggregatorRouteTransformName, "test-p1-source1"},
+				"sinks":      {"test-p1-sink1"},
+			},
+		},
+		{
+			name:            "Aggregator internal metrics without aggregator pipelines",
+			aggregator:      true,
+			internalMetrics: true,
+			pipelines:       []pipeline.Pipeline{newTestPipeline("p1", "")},
+			agent: map[string][]string{
+				"sources": {"test-p1-source1"},
+				"sinks":   {"test-p1-sink1"},
+			},
+			aggr: map[string][]string{
+				"sources": {config.AggregatorSourceName, config.InternalMetricsSourceName},
+				"sinks":   {"defaultSink", config.InternalMetricsSinkName},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			req := require.New(t)
+
+			vector := newTestVector(tc.aggregator)
+			vector.Spec.Aggregator.InternalMetrics = tc.internalMetrics
+			agentConfig, err := config.NewBuilder(vectoragent.NewController(vector, nil, nil), tc.pipelines...).GetByteConfig()
+			req.NoError(err)
+			req.Equal(tc.agent, getComponentNames(t, agentConfig))
+			_, err = configcheck.NewStructuralValidator(false).Validate(context.Background(), agentConfig)
+			req.NoError(err)
+
+			if !tc.aggregator {
+				return
+			}
+			aggregatorConfig, err := config.NewAggregatorBuilder(vectoraggregator.NewController(vector, nil, nil), tc.pipelines...).GetByteConfig()
+			req.NoError(err)
+			req.Equal(tc.aggr, getComponentNames(t, aggregatorConfig))
+			_, err = configcheck.NewStructuralValidator(false).Validate(context.Background(), aggregatorConfig)
+			req.NoError(err)
+		})
+	}
+}
+
+func TestBuilderAggregatorNotEnabled(t *testing.T) {
+	_, err := config.NewBuilder(newTestAgentController(), newTestPipeline("p1", ""), newTestPipeline("p2", vectorv1alpha1.PipelineRoleAggregator)).GetByteConfig()
+	require.ErrorIs(t, err, config.PipelineAggregatorError)
+	require.Equal(t, vectorv1alpha1.ReasonAggregatorNotEnabled, config.GetConditionReason(err))
+	pipelineErrors := config.GetPipelineErrors(err)
+	require.Len(t, pipelineErrors, 1)
+	require.ErrorIs(t, pipelineErrors["test/p2"], config.PipelineAggregatorError)
+}
+
+func TestBuilderValueRefs(t *testing.T) {
+	p := newTestPipeline("p1", "")
+	p.Spec.Sinks = &runtime.RawExtension{
+		Raw: []byte(`{"sink1":{"type":"http","inputs":["source1"],"uri":"http://example.com","encoding":{"codec":"json"},"auth":{"strategy":"basic","user":"vector","password":{"secretRef":{"name":"creds","key":"password"}}}}}`),
+	}
+
+	builder := config.NewBuilder(newTestAgentController(), p)
+	data, err := builder.GetByteConfig()
+	require.NoError(t, err)
+
+	ref := valueref.Ref{Kind: valueref.SecretKind, Namespace: "test", Name: "creds", Key: "password"}
+	require.Equal(t, []valueref.Ref{ref}, builder.GetValueRefs())
+	require.Contains(t, string(data), `"password":"${`+ref.EnvName()+`}"`)
+	require.NotContains(t, string(data), "secretRef")
+}
+
+func TestBuilderValueRefExternalNamespace(t *testing.T) {
+	p := newTestPipeline("p1", "")
+	p.Spec.Sinks = &runtime.RawExtension{
+		Raw: []byte(`{"sink1":{"type":"console","inputs":["source1"],"encoding":{"codec":"json"},"target":{"configMapRef":{"name":"cm","key":"target","namespace":"other"}}}}`),
+	}
+
+	_, err := config.NewBuilder(newTestAgentController(), p).GetByteConfig()
+	require.ErrorIs(t, err, valueref.ValueRefError)
+	require.Equal(t, vectorv1alpha1.ReasonInvalidValueRef, config.GetConditionReason(err))
+}
+
+func TestBuilderValueRefReservedEnv(t *testing.T) {
+	token := "${" + valueref.Ref{Kind: valueref.SecretKind, Namespace: "other", Name: "creds", Key: "password"}.EnvName() + "}"
+
+	type testCase struct {
+		name       string
+		sources    string
+		transforms string
+		sinks      string
+	}
+
+	testCases := []testCase{
+		{
+			name:  "Option key",
+			sinks: `{"sink1":{"type":"http","inputs":["source1"],"uri":"http://example.com","encoding":{"codec":"json"},"request":{"headers":{"` + token + `":"x"}}}}`,
+		},
+		{
+			name:       "Route condition",
+			transforms: `{"route":{"type":"route","inputs":["source1"],"route":{"leak":"\"` + token + `\" != \"\""}}}`,
+			sinks:      `{"sink1":{"type":"console","inputs":["route.leak"],"encoding":{"codec":"json"}}}`,
+		},
+		{
+			name:    "Source selector",
+			sources: `{"source1":{"type":"kubernetes_logs","extra_label_selector":"app=` + token + `"}}`,
+		},
+		{
+			name:  "Component name",
+			sinks: `{"` + token + `":{"type":"console","inputs":["source1"],"encoding":{"codec":"json"}}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newTestPipeline("p1", "")
+			if tc.sources != "" {
+				p.Spec.Sources = &runtime.RawExtension{Raw: []byte(tc.sources)}
+			}
+			if tc.transforms != "" {
+				p.Spec.Transforms = &runtime.RawExtension{Raw: []byte(tc.transforms)}
+			}
+			if tc.sinks != "" {
+				p.Spec.Sinks = &runtime.RawExtension{Raw: []byte(tc.sinks)}
+			}
+
+			_, err := config.NewBuilder(newTestAgentController(), p).GetByteConfig()
+			require.ErrorIs(t, err, valueref.ValueRefError)
+			require.Equal(t, vectorv1alpha1.ReasonInvalidValueRef, config.GetConditionReason(err))
+		})
+	}
+}
+
+func TestBuilderVRLEnvAccess(t *testing.T) {
+	type testCase struct {
+		name        string
+		cluster     bool
+		noAllowlist bool
+		transforms  string
+		typed       map[string]vectorv1alpha1.TransformSpec
+		wantErr     bool
+	}
+
+	cases := []testCase{
+		{
+			name:       "Remap without get_env_var",
+			transforms: `{"t":{"type":"remap","inputs":["source1"],"source":".env = \"prod\""}}`,
+		},
+		{
+			name:       "Remap with concatenated variable name",
+			transforms: `{"t":{"type":"remap","inputs":["source1"],"source":".secret = get_env_var!(\"VECTOR_\" + \"REF_0123456789ABCDEF\")"}}`,
+			wantErr:    true,
+		},
+		{
+			name:       "Filter condition",
+			transforms: `{"t":{"type":"filter","inputs":["source1"],"condition":{"type":"vrl","source":"get_env_var(\"TOKEN\") ?? \"\" != \"\""}}}`,
+			wantErr:    true,
+		},
+		{
+			name:       "Route condition",
+			transforms: `{"t":{"type":"route","inputs":["source1"],"route":{"a":"exists(get_env_var(\"HOME\"))"}}}`,
+			wantErr:    true,
+		},
+		{
+			name: "Typed remap",
+			typed: map[string]vectorv1alpha1.TransformSpec{
+				"t": {Inputs: []string{"source1"}, Remap: &vectorv1alpha1.RemapTransform{Source: ".x = get_env_var!(\"X\")"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "Cluster pipeline",
+			cluster:    true,
+			transforms: `{"t":{"type":"remap","inputs":["source1"],"source":".x = get_env_var!(\"X\")"}}`,
+		},
+		{
+			name:        "Allowlist is not set",
+			noAllowlist: true,
+			transforms:  `{"t":{"type":"remap","inputs":["source1"],"source":".x = get_env_var!(\"X\")"}}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			vp := newTestPipeline("p1", "")
+			vp.Spec.Sinks = &runtime.RawExtension{Raw: []byte(`{"sink1":{"type":"console","inputs":["t"],"encoding":{"codec":"json"}}}`)}
+			if tc.transforms != "" {
+				vp.Spec.Transforms = &runtime.RawExtension{Raw: []byte(tc.transforms)}
+			}
+			vp.Spec.TypedTransforms = tc.typed
+			var p pipeline.Pipeline = vp
+			if tc.cluster {
+				p = &vectorv1alpha1.ClusterVectorPipeline{
+					TypeMeta:   metav1.TypeMeta{Kind: vectorv1alpha1.ClusterPipelineKind},
+					ObjectMeta: metav1.ObjectMeta{Name: "p1"},
+					Spec:       vp.Spec,
+				}
+			}
+
+			v := newTestVector(false)
+			if !tc.noAllowlist {
+				v.Spec.EnvAllowlist = []vectorv1alpha1.EnvAllowlistRule{{Env: []string{"COMMON_TOKEN"}}}
+			}
+			_, err := config.NewBuilder(vectoragent.NewController(v, nil, nil), p).GetByteConfig()
+			if !tc.wantErr {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, config.PipelineEnvError)
+			require.Equal(t, vectorv1alpha1.ReasonEnvNotAllowed, config.GetConditionReason(err))
+		})
+	}
+}
+
+func TestBuilderComponentsCount(t *testing.T) {
+	v := newTestVector(false)
+	v.Spec.MergeKubernetesSources = true
+	v.Spec.MergeSinks = true
+	var pipelines []pipeline.Pipeline
+	for _, name := range []string{"p1", "p2"} {
+		p := newTestPipeline(name, "")
+		p.Spec.Sources = &runtime.RawExtension{
+			Raw: []byte(`{"source1":{"type":"kubernetes_logs","extra_label_selector":"app=` + name + `"}}`),
+		}
+		pipelines = append(pipelines, p)
+	}
+
+	b := config.NewBuilder(vectoragent.NewController(v, nil, nil), pipelines...)
+	_, err := b.GetByteConfig()
+	require.NoError(t, err)
+
+	beforeMerge, afterMerge := b.GetComponentsCount()
+	require.Equal(t, config.ComponentsCount{Sources: 2, Transforms: 0, Sinks: 2}, beforeMerge)
+	require.Equal(t, config.ComponentsCount{Sources: 1, Transforms: 1, Sinks: 1}, afterMerge)
+}
+
+func TestBuilderTypedComponents(t *testing.T) {
+	type testCase struct {
+		name       string
+		transforms *runtime.RawExtension
+		spec       vectorv1alpha1.VectorPipelineSpec
+		want       map[string]interface{}
+		wantErr    error
+	}
+
+	maxLineBytes := int64(65536)
+	password := &apiextensionsv1.JSON{Raw: []byte(`{"secretRef":{"name":"creds","key":"password"}}`)}
+	ref := valueref.Ref{Kind: valueref.SecretKind, Namespace: "test", Name: "creds", Key: "password"}
+
+	testCases := []testCase{
+		{
+			name: "Typed components",
+			spec: vectorv1alpha1.VectorPipelineSpec{
+				TypedSources: map[string]vectorv1alpha1.SourceSpec{
+					"source1": {KubernetesLogs: &vectorv1alpha1.KubernetesLogsSource{ExtraLabelSelector: "app=test", MaxLineBytes: &maxLineBytes}},
+				},
+				TypedTransforms: map[string]vectorv1alpha1.TransformSpec{
+					"transform1": {Inputs: []string{"source1"}, Remap: &vectorv1alpha1.RemapTransform{Source: ".level = \"info\""}},
+				},
+				TypedSinks: map[string]vectorv1alpha1.SinkSpec{
+					"sink1": {Inputs: []string{"transform1"}, Loki: &vectorv1alpha1.LokiSink{
+						Endpoint: "http://loki:3100",
+						Labels:   map[string]string{"app": "{{ kubernetes.pod_labels.app }}"},
+						Encoding: vectorv1alpha1.SinkEncoding{Codec: "json"},
+						Auth:     &vectorv1alpha1.SinkAuth{Strategy: "basic", User: "vector", Password: password},
+					}},
+				},
+			},
+			want: map[string]interface{}{
+				"sources": map[string]interface{}{
+					"test-p1-source1": map[string]interface{}{
+						"type":                           "kubernetes_logs",
+						"extra_label_selector":           "app=test",
+						"extra_namespace_label_selector": "kubernetes.io/metadata.name=test",
+						"max_line_bytes":                 float64(65536),
+					},
+				},
+				"transforms": map[string]interface{}{
+					"test-p1-transform1": map[string]interface{}{
+						"type":   "remap",
+						"inputs": []interface{}{"test-p1-source1"},
+						"source": ".level = \"info\"",
+					},
+				},
+				"sinks": map[string]interface{}{
+					"test-p1-sink1": map[string]interface{}{
+						"type":     "loki",
+						"inputs":   []interface{}{"test-p1-transform1"},
+						"endpoint": "http://loki:3100",
+						"labels":   map[string]interface{}{"app": "{{ kubernetes.pod_labels.app }}"},
+						"encoding": map[string]interface{}{"codec": "json"},
+						"auth":     map[string]interface{}{"strategy": "basic", "user": "vector", "password": "${" + ref.EnvName() + "}"},
+					},
+				},
+			},
+		},
+		{
+			name:       "Raw and typed components",
+			transforms: &runtime.RawExtension{Raw: []byte(`{"transform1":{"type":"remap","inputs":["source1"],"source":"."}}`)},
+			spec: vectorv1alpha1.VectorPipelineSpec{
+				TypedSources: map[string]vectorv1alpha1.SourceSpec{
+					"source1": {KubernetesLogs: &vectorv1alpha1.KubernetesLogsSource{}},
+				},
+				TypedSinks: map[string]vectorv1alpha1.SinkSpec{
+					"sink1": {Inputs: []string{"transform1"}, Kafka: &vectorv1alpha1.KafkaSink{
+						BootstrapServers: "kafka:9092",
+						Topic:            "logs",
+						Encoding:         vectorv1alpha1.SinkEncoding{Codec: "json"},
+					}},
+				},
+			},
+			want: map[string]interface{}{
+				"sources": map[string]interface{}{
+					"test-p1-source1": map[string]interface{}{
+						"type":                           "kubernetes_logs",
+						"extra_namespace_label_selector": "kubernetes.io/metadata.name=test",
+					},
+				},
+				"transforms": map[string]interface{}{
+					"test-p1-transform1": map[string]interface{}{
+						"type":   "remap",
+						"inputs": []interface{}{"test-p1-source1"},
+						"source": ".",
+					},
+				},
+				"sinks": map[string]interface{}{
+					"test-p1-sink1": map[string]interface{}{
+						"type":              "kafka",
+						"inputs":            []interface{}{"test-p1-transform1"},
+						"bootstrap_servers": "kafka:9092",
+						"topic":             "logs",
+						"encoding":          map[string]interface{}{"codec": "json"},
+					},
+				},
+			},
+		},
+		{
+			name:       "Raw and typed components with the same name",
+			transforms: &runtime.RawExtension{Raw: []byte(`{"transform1":{"type":"remap","inputs":["source1"],"source":"."}}`)},
+			spec: vectorv1alpha1.VectorPipelineSpec{
+				TypedTransforms: map[string]vectorv1alpha1.TransformSpec{
+					"transform1": {Inputs: []string{"source1"}, Filter: &vectorv1alpha1.FilterTransform{Condition: "true"}},
+				},
+			},
+			wantErr: config.PipelineComponentError,
+		},
+		{
+			name: "Several component types",
+			spec: vectorv1alpha1.VectorPipelineSpec{
+				TypedTransforms: map[string]vectorv1alpha1.TransformSpec{
+					"transform1": {
+						Inputs: []string{"source1"},
+						Filter: &vectorv1alpha1.FilterTransform{Condition: "true"},
+						Remap:  &vectorv1alpha1.RemapTransform{Source: "."},
+					},
+				},
+			},
+			wantErr: config.PipelineComponentError,
+		},
+		{
+			name: "Component type is not set",
+			spec: vectorv1alpha1.VectorPipelineSpec{
+				TypedSinks: map[string]vectorv1alpha1.SinkSpec{
+					"sink2": {Inputs: []string{"source1"}},
+				},
+			},
+			wantErr: config.PipelineComponentError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newTestPipeline("p1", "")
+			p.Spec.Transforms = tc.transforms
+			p.Spec.TypedSources = tc.spec.TypedSources
+			p.Spec.TypedTransforms = tc.spec.TypedTransforms
+			p.Spec.TypedSinks = tc.spec.TypedSinks
+			if tc.want != nil {
+				p.Spec.Sources = nil
+				p.Spec.Sinks = nil
+			}
+
+			data, err := config.NewBuilder(newTestAgentController(), p).GetByteConfig()
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				require.Equal(t, vectorv1alpha1.ReasonInvalidComponent, config.GetConditionReason(err))
+				return
+			}
+			require.NoError(t, err)
+
+			cfg := make(map[string]interface{})
+			require.NoError(t, json.Unmarshal(data, &cfg))
+			for _, key := range []string{"sources", "transforms", "sinks"} {
+				require.Equal(t, tc.want[key], cfg[key], key)
+			}
+		})
+	}
+}
+
+func TestGetPipelineErrors(t *testing.T) {
+	p1 := newTestPipeline("p1", "")
+	p1.Spec.Sinks = &runtime.RawExtension{
+		Raw: []byte(`{"sink1":{"type":"console","inputs":["sourc1"],"encoding":{"codec":"json"}}}`),
+	}
+	p2 := newTestPipeline("p2", "")
+
+	_, err := config.NewBuilder(newTestAgentController(), p1, p2).GetByteConfig()
+	pipelineErrors := config.GetPipelineErrors(err)
+	require.Len(t, pipelineErrors, 1)
+	require.ErrorIs(t, pipelineErrors["test/p1"], config.PipelineTopologyError)
+	require.NotContains(t, pipelineErrors["test/p1"].Error(), "test/p2")
+
+	require.Nil(t, config.GetPipelineErrors(config.PipelineTypeError))
+
+	p3 := newTestPipeline("p3", "")
+	p3.Spec.Sources = &runtime.RawExtension{Raw: []byte(`{"source1":{"type":"file"}}`)}
+	_, err = config.NewBuilder(newTestAgentController(), p2, p3).GetByteConfig()
+	require.ErrorIs(t, err, config.PipelineTypeError)
+	pipelineErrors = config.GetPipelineErrors(err)
+	require.Len(t, pipelineErrors, 1)
+	require.Equal(t, config.PipelineTypeError, pipelineErrors["test/p3"])
+}
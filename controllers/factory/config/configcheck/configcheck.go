@@ -19,19 +19,19 @@ package configcheck
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/rand"
 	"time"
 
+	"github.com/kaasops/vector-operator/controllers/factory/metrics"
 	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	"github.com/kaasops/vector-operator/controllers/factory/valueref"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
-	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
@@ -41,7 +41,7 @@ type ConfigCheck struct {
 	Config []byte
 
 	Client    client.Client
-	ClientSet *kubernetes.Clientset
+	ClientSet kubernetes.Interface
 
 	Name                     string
 	Namespace                string
@@ -59,6 +59,17 @@ type ConfigCheck struct {
 	ConfigReloaderImage      string
 	ConfigReloaderResources  corev1.ResourceRequirements
 	ConfigCheckTimeout       time.Duration
+	// ValueRefs are Secret and ConfigMap keys referenced by config, they are passed to configcheck pod as env
+	ValueRefs []valueref.Ref
+	// VectorName is name of checked Vector, config check pods and Secrets are labeled with it
+	VectorName string
+	// Recorder emits config check events on EventObjects: Vector or pipelines, that initiated check
+	Recorder     record.EventRecorder
+	EventObjects []runtime.Object
+	// ComponentResolver maps components in failed config check output back to pipelines
+	ComponentResolver ComponentResolver
+	// Queue limits number of running configcheck pods. Checks are not limited, if it is nil
+	Queue *Queue
 }
 
 func New(
@@ -91,6 +102,7 @@ func New(
 		ClientSet:                cs,
 		Name:                     va.Name,
 		Namespace:                va.Namespace,
+		VectorName:               va.Name,
 		Image:                    image,
 		ImagePullPolicy:          va.Spec.Agent.ImagePullPolicy,
 		ImagePullSecrets:         va.Spec.Agent.ImagePullSecrets,
@@ -106,7 +118,79 @@ func New(
 	}
 }
 
+// NewAggregator returns ConfigCheck for Vector Aggregator config
+func NewAggregator(
+	config []byte,
+	c client.Client,
+	cs *kubernetes.Clientset,
+	va *vectorv1alpha1.Vector,
+	timeout time.Duration,
+) *ConfigCheck {
+	return &ConfigCheck{
+		Config:                   config,
+		Client:                   c,
+		ClientSet:                cs,
+		Name:                     va.Name + "-aggregator",
+		Namespace:                va.Namespace,
+		VectorName:               va.Name,
+		Image:                    va.Spec.Aggregator.Image,
+		ImagePullPolicy:          va.Spec.Aggregator.ImagePullPolicy,
+		ImagePullSecrets:         va.Spec.Aggregator.ImagePullSecrets,
+		Envs:                     va.Spec.Aggregator.Env,
+		Tolerations:              va.Spec.Aggregator.Tolerations,
+		Resources:                va.Spec.Aggregator.Resources,
+		SecurityContext:          va.Spec.Aggregator.SecurityContext,
+		ContainerSecurityContext: va.Spec.Aggregator.ContainerSecurityContext,
+		ConfigCheckTimeout:       timeout,
+	}
+}
+
+// Run checks config in configcheck pod, when Queue has free slot. Duration and result of check are recorded in metrics
 func (cc *ConfigCheck) Run(ctx context.Context) (string, error) {
+	release, err := cc.Queue.Acquire(ctx, cc.Initiator, cc.ConfigCheckTimeout)
+	if err != nil {
+		if errors.Is(err, ConfigcheckTimeoutError) {
+			cc.event(corev1.EventTypeWarning, k8s.EventReasonConfigCheckTimeout, fmt.Sprintf("Config check of %s/%s: %s", cc.Namespace, cc.Name, err))
+		}
+		return "", err
+	}
+	defer release()
+
+	start := time.Now()
+	reason, err := cc.run(ctx)
+	metrics.ObserveConfigCheck(cc.Initiator, getMetricsResult(err), time.Since(start))
+	switch {
+	case err == nil:
+		cc.event(corev1.EventTypeNormal, k8s.EventReasonConfigCheckPassed, fmt.Sprintf("Config check of %s/%s passed", cc.Namespace, cc.Name))
+	case errors.Is(err, ConfigcheckTimeoutError):
+		cc.event(corev1.EventTypeWarning, k8s.EventReasonConfigCheckTimeout, fmt.Sprintf("Config check of %s/%s: %s", cc.Namespace, cc.Name, err))
+	case errors.Is(err, ConfigcheckInfrastructureError):
+		cc.event(corev1.EventTypeWarning, k8s.EventReasonConfigCheckInfrastructureFailure, fmt.Sprintf("Config check of %s/%s: %s", cc.Namespace, cc.Name, err))
+	}
+	return reason, err
+}
+
+func (cc *ConfigCheck) event(eventtype, reason, message string) {
+	for _, obj := range cc.EventObjects {
+		k8s.Event(cc.Recorder, obj, eventtype, reason, message)
+	}
+}
+
+func getMetricsResult(err error) string {
+	switch {
+	case err == nil:
+		return metrics.ResultPassed
+	case errors.Is(err, ValidationError):
+		return metrics.ResultFailed
+	case errors.Is(err, ConfigcheckTimeoutError):
+		return metrics.ResultTimeout
+	case errors.Is(err, ConfigcheckInfrastructureError):
+		return metrics.ResultInfrastructureFailure
+	}
+	return metrics.ResultError
+}
+
+func (cc *ConfigCheck) run(ctx context.Context) (string, error) {
 	log := log.FromContext(ctx).WithValues("Vector ConfigCheck", cc.Initiator)
 	log.Info("================= Started ConfigCheck =================")
 
@@ -121,35 +205,55 @@ func (cc *ConfigCheck) Run(ctx context.Context) (string, error) {
 		return "", err
 	}
 
-	vectorConfigCheckPod := cc.createVectorConfigCheckPod()
-
+	// Finished Job is removed with pods and Secrets after ttlSecondsAfterFinished. Job is removed at once,
+	// if check is not passed, so failed pod is not retried
+	var vectorConfigCheckJob *batchv1.Job
+	passed := false
 	defer func() {
-		err = cc.cleanup(ctx, vectorConfigCheckSecret)
+		if passed {
+			return
+		}
+		if err := cc.cleanup(ctx, vectorConfigCheckSecret, vectorConfigCheckJob); err != nil {
+			log.Error(err, "Failed to remove config check resources")
+		}
 	}()
 
 	if err = k8s.CreateOrUpdateResource(ctx, vectorConfigCheckSecret, cc.Client); err != nil {
 		return "", err
 	}
 
-	// Set OwnerReference to pod
-	if err = controllerutil.SetOwnerReference(vectorConfigCheckSecret, vectorConfigCheckPod, cc.Client.Scheme()); err != nil {
+	if err = cc.ensureVectorConfigCheckEnv(ctx, vectorConfigCheckSecret); err != nil {
+		if errors.Is(err, valueref.ValueRefNotFoundError) {
+			return err.Error(), ValueRefNotFoundError
+		}
 		return "", err
 	}
 
-	err = k8s.CreatePod(ctx, vectorConfigCheckPod, cc.Client)
-	if err != nil {
+	job := cc.createVectorConfigCheckJob()
+	if err = k8s.CreateJob(ctx, job, cc.Client); err != nil {
 		return "", err
 	}
+	vectorConfigCheckJob = job
 
-	reason, err := cc.getCheckResult(ctx, vectorConfigCheckPod)
+	if err = cc.setVectorConfigCheckConfigOwner(ctx, vectorConfigCheckSecret, job); err != nil {
+		return "", err
+	}
+	cc.event(corev1.EventTypeNormal, k8s.EventReasonConfigCheckStarted, fmt.Sprintf("Config check of %s/%s started in Job %s", cc.Namespace, cc.Name, job.Name))
+
+	reason, err := cc.getCheckResult(ctx, job)
 	if err != nil {
 		if errors.Is(err, ValidationError) {
+			// Raw log tail is kept as reason, if output format is not recognized
+			if configErrors := ParseValidateOutput(reason, cc.ComponentResolver); len(configErrors) != 0 {
+				return FormatConfigErrors(configErrors), &ValidateOutputError{ConfigErrors: configErrors}
+			}
 			return reason, err
 		}
 		return "", err
 	}
 
-	return reason, err
+	passed = true
+	return reason, nil
 }
 
 func (cc *ConfigCheck) ensureVectorConfigCheckRBAC(ctx context.Context) error {
@@ -170,12 +274,23 @@ func labelsForVectorConfigCheck() map[string]string {
 	}
 }
 
+// labelsForVectorConfigCheckInstance returns labels of config check pods and Secrets of one Vector
+func labelsForVectorConfigCheckInstance(vectorName string) map[string]string {
+	labels := labelsForVectorConfigCheck()
+	labels[k8s.InstanceLabelKey] = vectorName
+	return labels
+}
+
 func (cc *ConfigCheck) getNameVectorConfigCheck() string {
 	n := "configcheck" + "-" + cc.Name + "-" + cc.Hash
 
 	return n
 }
 
+func (cc *ConfigCheck) getNameVectorConfigCheckEnv() string {
+	return cc.getNameVectorConfigCheck() + "-env"
+}
+
 func randStringRunes() string {
 	var letterRunes = []rune("abcdefghijklmnopqrstuvwxyz")
 
@@ -185,108 +300,3 @@ func randStringRunes() string {
 	}
 	return string(b)
 }
-
-func (cc *ConfigCheck) getCheckResult(ctx context.Context, pod *corev1.Pod) (reason string, err error) {
-	log := log.FromContext(ctx).WithValues("Vector ConfigCheck", pod.Name)
-	log.Info("Trying to get configcheck result")
-
-	watcher, err := cc.ClientSet.CoreV1().Pods(cc.Namespace).Watch(ctx, metav1.ListOptions{
-		FieldSelector: fields.OneTermEqualSelector(metav1.ObjectNameField, pod.Name).String(),
-		// LabelSelector: labelsForVectorConfigCheck(),
-	})
-
-	if err != nil {
-		log.Error(err, "cannot create Pod event watcher")
-		return "", err
-	}
-
-	defer watcher.Stop()
-
-	for {
-		select {
-		case e := <-watcher.ResultChan():
-			if e.Object == nil {
-				return "", nil
-			}
-			pod, ok := e.Object.(*corev1.Pod)
-			if !ok {
-				continue
-			}
-			switch e.Type {
-			case watch.Modified:
-				if pod.DeletionTimestamp != nil {
-					continue
-				}
-				switch pod.Status.Phase {
-				case corev1.PodSucceeded:
-					log.Info("Config Check completed successfully")
-					return "", nil
-				case corev1.PodFailed:
-					log.Info("Config Check Failed")
-					reason, err := k8s.GetPodLogs(ctx, pod, cc.ClientSet)
-					if err != nil {
-						return "", err
-					}
-					return reason, ValidationError
-				}
-			}
-		case <-ctx.Done():
-			watcher.Stop()
-			return "", nil
-		case <-time.After(cc.ConfigCheckTimeout):
-			watcher.Stop()
-			return "", ConfigcheckTimeoutError
-		}
-	}
-}
-
-func (cc *ConfigCheck) cleanup(ctx context.Context, secret *corev1.Secret) error {
-
-	nn := types.NamespacedName{
-		Name:      secret.Name,
-		Namespace: secret.Namespace,
-	}
-	secret, err := k8s.GetSecret(ctx, nn, cc.Client)
-	if err != nil {
-		return err
-	}
-	if err := k8s.DeleteSecret(ctx, secret, cc.Client); err != nil {
-		return err
-	}
-	return nil
-}
-
-func (cc *ConfigCheck) CleanAll(ctx context.Context) error {
-	listOpts, err := cc.configCheckListOpts()
-	if err != nil {
-		return err
-	}
-	secrets, err := k8s.ListSecret(ctx, cc.Client, listOpts)
-	if err != nil {
-		return err
-	}
-	for _, secret := range secrets {
-		if err := k8s.DeleteSecret(ctx, &secret, cc.Client); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func (cc *ConfigCheck) configCheckListOpts() (client.ListOptions, error) {
-	configCheckLabels := labelsForVectorConfigCheck()
-	var requirements []labels.Requirement
-	for k, v := range configCheckLabels {
-		r, err := labels.NewRequirement(k, "==", []string{v})
-		if err != nil {
-			return client.ListOptions{}, err
-		}
-		requirements = append(requirements, *r)
-	}
-	labelsSelector := labels.NewSelector().Add(requirements...)
-
-	return client.ListOptions{
-		LabelSelector: labelsSelector,
-		Namespace:     cc.Namespace,
-	}, nil
-}
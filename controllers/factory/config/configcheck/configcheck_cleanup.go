@@ -0,0 +1,84 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcheck
+
+import (
+	"context"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Cleanup removes config check Jobs, pods and Secrets left by Vector. Config check ServiceAccount is shared
+// by Vectors in namespace, so it is removed with the last Vector
+func Cleanup(ctx context.Context, c client.Client, cs kubernetes.Interface, v *vectorv1alpha1.Vector) error {
+	log := log.FromContext(ctx).WithValues("Vector ConfigCheck", v.Name)
+	log.Info("start Cleanup Vector ConfigCheck resources")
+
+	listOpts := metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labelsForVectorConfigCheckInstance(v.Name)).String(),
+	}
+
+	jobs, err := cs.BatchV1().Jobs(v.Namespace).List(ctx, listOpts)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs.Items {
+		if err := cs.BatchV1().Jobs(v.Namespace).Delete(ctx, job.Name, deleteInBackground()); err != nil && !api_errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	pods, err := cs.CoreV1().Pods(v.Namespace).List(ctx, listOpts)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if err := cs.CoreV1().Pods(v.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil && !api_errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	secrets, err := cs.CoreV1().Secrets(v.Namespace).List(ctx, listOpts)
+	if err != nil {
+		return err
+	}
+	for _, secret := range secrets.Items {
+		if err := cs.CoreV1().Secrets(v.Namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil && !api_errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	vectors := vectorv1alpha1.VectorList{}
+	if err := c.List(ctx, &vectors, client.InNamespace(v.Namespace)); err != nil {
+		return err
+	}
+	for _, vector := range vectors.Items {
+		if vector.Name != v.Name && vector.DeletionTimestamp == nil {
+			return nil
+		}
+	}
+
+	cc := &ConfigCheck{Namespace: v.Namespace}
+	return k8s.DeleteResource(ctx, cc.createVectorConfigCheckServiceAccount(), c)
+}
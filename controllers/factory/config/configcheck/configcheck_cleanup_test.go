@@ -0,0 +1,119 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcheck_test
+
+import (
+	"context"
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/config/configcheck"
+	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCleanup(t *testing.T) {
+	configCheckMeta := func(name, vector string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "test",
+			Labels: map[string]string{
+				k8s.ManagedByLabelKey: "vector-operator",
+				k8s.NameLabelKey:      "vector-configcheck",
+				k8s.ComponentLabelKey: "ConfigCheck",
+				k8s.InstanceLabelKey:  vector,
+			},
+		}
+	}
+	newVector := func(name string) *vectorv1alpha1.Vector {
+		return &vectorv1alpha1.Vector{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"}}
+	}
+
+	type testCase struct {
+		name         string
+		vectors      []client.Object
+		wantSAExists bool
+	}
+
+	cases := []testCase{
+		{
+			name:         "Last Vector in namespace",
+			vectors:      []client.Object{newVector("v1")},
+			wantSAExists: false,
+		},
+		{
+			name:         "Other Vector in namespace",
+			vectors:      []client.Object{newVector("v1"), newVector("v2")},
+			wantSAExists: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			require.NoError(t, clientgoscheme.AddToScheme(scheme))
+			require.NoError(t, vectorv1alpha1.AddToScheme(scheme))
+
+			sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "vector-configcheck", Namespace: "test"}}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(tc.vectors, sa)...).Build()
+			cs := fakeclientset.NewSimpleClientset(
+				&batchv1.Job{ObjectMeta: configCheckMeta("configcheck-v1-abcde", "v1")},
+				&batchv1.Job{ObjectMeta: configCheckMeta("configcheck-v2-abcde", "v2")},
+				&corev1.Pod{ObjectMeta: configCheckMeta("configcheck-v1-abcde", "v1")},
+				&corev1.Secret{ObjectMeta: configCheckMeta("configcheck-v1-abcde", "v1")},
+				&corev1.Secret{ObjectMeta: configCheckMeta("configcheck-v1-abcde-env", "v1")},
+				&corev1.Pod{ObjectMeta: configCheckMeta("configcheck-v2-abcde", "v2")},
+				&corev1.Secret{ObjectMeta: configCheckMeta("configcheck-v2-abcde", "v2")},
+			)
+
+			require.NoError(t, configcheck.Cleanup(ctx, c, cs, newVector("v1")))
+
+			jobs, err := cs.BatchV1().Jobs("test").List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+			require.Len(t, jobs.Items, 1)
+			require.Equal(t, "configcheck-v2-abcde", jobs.Items[0].Name)
+
+			pods, err := cs.CoreV1().Pods("test").List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+			require.Len(t, pods.Items, 1)
+			require.Equal(t, "configcheck-v2-abcde", pods.Items[0].Name)
+
+			secrets, err := cs.CoreV1().Secrets("test").List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+			require.Len(t, secrets.Items, 1)
+			require.Equal(t, "configcheck-v2-abcde", secrets.Items[0].Name)
+
+			err = c.Get(ctx, types.NamespacedName{Name: "vector-configcheck", Namespace: "test"}, &corev1.ServiceAccount{})
+			if tc.wantSAExists {
+				require.NoError(t, err)
+			} else {
+				require.True(t, api_errors.IsNotFound(err))
+			}
+		})
+	}
+}
@@ -20,14 +20,17 @@ import (
 	"context"
 
 	"github.com/kaasops/vector-operator/controllers/factory/utils/compression"
+	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	"github.com/kaasops/vector-operator/controllers/factory/valueref"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 func (cc *ConfigCheck) createVectorConfigCheckConfig(ctx context.Context) (*corev1.Secret, error) {
 	log := log.FromContext(ctx).WithValues("Vector ConfigCheck", cc.Initiator)
-	labels := labelsForVectorConfigCheck()
+	labels := labelsForVectorConfigCheckInstance(cc.VectorName)
 	var data []byte = cc.Config
 
 	if cc.CompressedConfig {
@@ -49,3 +52,24 @@ func (cc *ConfigCheck) createVectorConfigCheckConfig(ctx context.Context) (*core
 
 	return secret, nil
 }
+
+// ensureVectorConfigCheckEnv saves referenced values to Secret, that is removed with config Secret
+func (cc *ConfigCheck) ensureVectorConfigCheckEnv(ctx context.Context, configSecret *corev1.Secret) error {
+	data, err := valueref.Resolve(ctx, cc.ClientSet, cc.ValueRefs)
+	if err != nil || len(data) == 0 {
+		return err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cc.getNameVectorConfigCheckEnv(),
+			Namespace: cc.Namespace,
+			Labels:    labelsForVectorConfigCheckInstance(cc.VectorName),
+		},
+		Data: data,
+	}
+	if err := controllerutil.SetOwnerReference(configSecret, secret, cc.Client.Scheme()); err != nil {
+		return err
+	}
+	return k8s.CreateOrUpdateResource(ctx, secret, cc.Client)
+}
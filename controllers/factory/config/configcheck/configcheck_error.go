@@ -18,9 +18,79 @@ package configcheck
 
 import (
 	"errors"
+	"fmt"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
 )
 
 var (
 	ValidationError         = errors.New("config validation error")
 	ConfigcheckTimeoutError = errors.New("timeout waiting configcheck pod result")
+	// ConfigcheckInfrastructureError is returned, when configcheck pod can't run because of cluster problems:
+	// image pull failure, unschedulable or repeatedly evicted pod. Config is not checked
+	ConfigcheckInfrastructureError = errors.New("configcheck pod can't run")
+	// ValueRefNotFoundError is returned, when Secret or ConfigMap key referenced by config doesn't exist
+	ValueRefNotFoundError = fmt.Errorf("%w: referenced value not found", ValidationError)
 )
+
+// GetConditionReason returns status condition reason for config check error
+func GetConditionReason(err error) string {
+	if errors.Is(err, ConfigcheckTimeoutError) {
+		return vectorv1alpha1.ReasonConfigCheckTimeout
+	}
+	if errors.Is(err, ConfigcheckInfrastructureError) {
+		return vectorv1alpha1.ReasonConfigCheckInfrastructureFailure
+	}
+	if errors.Is(err, ValueRefNotFoundError) {
+		return vectorv1alpha1.ReasonValueRefNotFound
+	}
+	return vectorv1alpha1.ReasonValidationFailed
+}
+
+// IsResultUnknown returns true, if config check didn't produce result: it timed out or configcheck pod couldn't run.
+// Config validity is unknown in this case
+func IsResultUnknown(err error) bool {
+	return errors.Is(err, ConfigcheckTimeoutError) || errors.Is(err, ConfigcheckInfrastructureError)
+}
+
+// PodFailureError is ConfigcheckInfrastructureError with reason reported by Kubernetes for configcheck pod or Job
+type PodFailureError struct {
+	// Name is name of configcheck pod or Job
+	Name    string
+	Reason  string
+	Message string
+}
+
+func (e *PodFailureError) Error() string {
+	msg := fmt.Sprintf("%s: %s: %s", ConfigcheckInfrastructureError, e.Name, e.Reason)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e *PodFailureError) Unwrap() error {
+	return ConfigcheckInfrastructureError
+}
+
+// ValidateOutputError is ValidationError with errors parsed from vector validate output
+type ValidateOutputError struct {
+	ConfigErrors []vectorv1alpha1.ConfigError
+}
+
+func (e *ValidateOutputError) Error() string {
+	return ValidationError.Error()
+}
+
+func (e *ValidateOutputError) Unwrap() error {
+	return ValidationError
+}
+
+// GetConfigErrors returns config errors parsed from vector validate output or nil, if err doesn't contain them
+func GetConfigErrors(err error) []vectorv1alpha1.ConfigError {
+	var outputErr *ValidateOutputError
+	if errors.As(err, &outputErr) {
+		return outputErr.ConfigErrors
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcheck
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// configCheckBackoffLimit is number of retries of config check pod, failed because of eviction or node failure.
+	// Pod, that ran vector validate, is not retried: Job is deleted as soon as its result is received
+	configCheckBackoffLimit int32 = 2
+	// configCheckTTLSecondsAfterFinished is time, after which finished Job is removed with its pods and Secrets
+	configCheckTTLSecondsAfterFinished int32 = 300
+	// jobNameLabelKey is set by Job controller on Job pods
+	jobNameLabelKey = "job-name"
+	// configCheckContainerName is name of container running vector validate
+	configCheckContainerName = "config-check"
+)
+
+const reasonOOMKilled = "OOMKilled"
+
+// containerWaitingFailures are container waiting reasons, reported by kubelet, that are not fixed by waiting:
+// image can't be pulled or container can't be created from pod spec
+var containerWaitingFailures = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"ErrImageNeverPull":          true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+func (cc *ConfigCheck) createVectorConfigCheckJob() *batchv1.Job {
+	pod := cc.createVectorConfigCheckPod()
+	backoffLimit := configCheckBackoffLimit
+	ttl := configCheckTTLSecondsAfterFinished
+
+	job := &batchv1.Job{
+		ObjectMeta: pod.ObjectMeta,
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			TTLSecondsAfterFinished: &ttl,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: pod.Labels,
+				},
+				Spec: pod.Spec,
+			},
+		},
+	}
+	if deadline := int64(cc.ConfigCheckTimeout.Seconds()); deadline > 0 {
+		job.Spec.ActiveDeadlineSeconds = &deadline
+	}
+
+	return job
+}
+
+// setVectorConfigCheckConfigOwner makes Job owner of config Secret, so Secrets are removed with Job
+func (cc *ConfigCheck) setVectorConfigCheckConfigOwner(ctx context.Context, secret *corev1.Secret, job *batchv1.Job) error {
+	patch := client.MergeFrom(secret.DeepCopy())
+	if err := controllerutil.SetOwnerReference(job, secret, cc.Client.Scheme()); err != nil {
+		return err
+	}
+	return cc.Client.Patch(ctx, secret, patch)
+}
+
+// GetPodFailure returns PodFailureError, if config check pod can't run or was stopped before vector validate
+// finished. Retryable failures (eviction, node failure, OOM) are retried by Job, others are not fixed by retry
+func GetPodFailure(pod *corev1.Pod) (failure *PodFailureError, retryable bool) {
+	newFailure := func(reason, message string) *PodFailureError {
+		return &PodFailureError{Name: pod.Name, Reason: reason, Message: message}
+	}
+
+	if pod.Status.Phase == corev1.PodFailed {
+		if pod.Status.Reason != "" {
+			return newFailure(pod.Status.Reason, pod.Status.Message), true
+		}
+		status := getContainerStatus(pod.Status.ContainerStatuses, configCheckContainerName)
+		if status == nil || status.State.Terminated == nil {
+			return newFailure("ContainerNotStarted", "config check container didn't run"), true
+		}
+		if status.State.Terminated.Reason == reasonOOMKilled {
+			return newFailure(reasonOOMKilled, status.State.Terminated.Message), true
+		}
+		return nil, false
+	}
+
+	// Init containers are checked too, config reloader unpacks compressed config before config check
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if w := status.State.Waiting; w != nil && containerWaitingFailures[w.Reason] {
+			return newFailure(w.Reason, status.Name+": "+w.Message), false
+		}
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+			return newFailure(cond.Reason, cond.Message), false
+		}
+	}
+	return nil, false
+}
+
+func getContainerStatus(statuses []corev1.ContainerStatus, name string) *corev1.ContainerStatus {
+	for i := range statuses {
+		if statuses[i].Name == name {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+// getJobFailure returns error for failed Job: ConfigcheckTimeoutError, if Job exceeded activeDeadlineSeconds, or
+// PodFailureError, if pods failed more than backoffLimit times
+func getJobFailure(job *batchv1.Job) error {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type != batchv1.JobFailed || cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		if cond.Reason == "DeadlineExceeded" {
+			return ConfigcheckTimeoutError
+		}
+		return &PodFailureError{Name: job.Name, Reason: cond.Reason, Message: cond.Message}
+	}
+	return nil
+}
+
+func isJobComplete(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobComplete && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func (cc *ConfigCheck) getCheckResult(ctx context.Context, job *batchv1.Job) (reason string, err error) {
+	log := log.FromContext(ctx).WithValues("Vector ConfigCheck", job.Name)
+	log.Info("Trying to get configcheck result")
+
+	podWatcher, err := cc.ClientSet.CoreV1().Pods(job.Namespace).Watch(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(map[string]string{jobNameLabelKey: job.Name}).String(),
+	})
+	if err != nil {
+		log.Error(err, "cannot create Pod event watcher")
+		return "", err
+	}
+	defer podWatcher.Stop()
+
+	jobWatcher, err := cc.ClientSet.BatchV1().Jobs(job.Namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(metav1.ObjectNameField, job.Name).String(),
+	})
+	if err != nil {
+		log.Error(err, "cannot create Job event watcher")
+		return "", err
+	}
+	defer jobWatcher.Stop()
+
+	timeout := time.NewTimer(cc.ConfigCheckTimeout)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case e, ok := <-podWatcher.ResultChan():
+			if !ok {
+				return "", errors.New("configcheck pod watch closed")
+			}
+			pod, ok := e.Object.(*corev1.Pod)
+			if !ok || e.Type == watch.Deleted || pod.DeletionTimestamp != nil {
+				continue
+			}
+			if pod.Status.Phase == corev1.PodSucceeded {
+				log.Info("Config Check completed successfully")
+				return "", nil
+			}
+			failure, retryable := GetPodFailure(pod)
+			if failure != nil {
+				if retryable {
+					log.Info("Config Check pod failed, waiting for retry", "reason", failure.Reason)
+					continue
+				}
+				return "", failure
+			}
+			if pod.Status.Phase == corev1.PodFailed {
+				log.Info("Config Check Failed")
+				reason, err := k8s.GetPodLogs(ctx, pod, cc.ClientSet)
+				if err != nil {
+					return "", err
+				}
+				return reason, ValidationError
+			}
+		case e, ok := <-jobWatcher.ResultChan():
+			if !ok {
+				return "", errors.New("configcheck Job watch closed")
+			}
+			job, ok := e.Object.(*batchv1.Job)
+			if !ok || e.Type == watch.Deleted {
+				continue
+			}
+			if isJobComplete(job) {
+				log.Info("Config Check completed successfully")
+				return "", nil
+			}
+			if err := getJobFailure(job); err != nil {
+				return "", err
+			}
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-timeout.C:
+			return "", ConfigcheckTimeoutError
+		}
+	}
+}
+
+// cleanup removes Job with pods and Secrets. Job is nil, if it wasn't created
+func (cc *ConfigCheck) cleanup(ctx context.Context, secret *corev1.Secret, job *batchv1.Job) error {
+	if job != nil {
+		if err := cc.Client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return k8s.DeleteResource(ctx, secret, cc.Client)
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcheck_test
+
+import (
+	"errors"
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/config/configcheck"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetPodFailure(t *testing.T) {
+	type testCase struct {
+		name          string
+		status        corev1.PodStatus
+		wantReason    string
+		wantRetryable bool
+	}
+
+	terminated := func(reason string, exitCode int32) []corev1.ContainerStatus {
+		return []corev1.ContainerStatus{{
+			Name:  "config-check",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: reason, ExitCode: exitCode}},
+		}}
+	}
+	waiting := func(reason string) []corev1.ContainerStatus {
+		return []corev1.ContainerStatus{{
+			Name:  "config-check",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: "message"}},
+		}}
+	}
+
+	cases := []testCase{
+		{
+			name:   "Validation failed",
+			status: corev1.PodStatus{Phase: corev1.PodFailed, ContainerStatuses: terminated("Error", 78)},
+		},
+		{
+			name:   "Running",
+			status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		{
+			name:          "Evicted",
+			status:        corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted", Message: "The node was low on resource: memory"},
+			wantReason:    "Evicted",
+			wantRetryable: true,
+		},
+		{
+			name:          "OOMKilled",
+			status:        corev1.PodStatus{Phase: corev1.PodFailed, ContainerStatuses: terminated("OOMKilled", 137)},
+			wantReason:    "OOMKilled",
+			wantRetryable: true,
+		},
+		{
+			name:          "Init container failed",
+			status:        corev1.PodStatus{Phase: corev1.PodFailed},
+			wantReason:    "ContainerNotStarted",
+			wantRetryable: true,
+		},
+		{
+			name:       "ImagePullBackOff",
+			status:     corev1.PodStatus{Phase: corev1.PodPending, ContainerStatuses: waiting("ImagePullBackOff")},
+			wantReason: "ImagePullBackOff",
+		},
+		{
+			name:       "CreateContainerConfigError",
+			status:     corev1.PodStatus{Phase: corev1.PodPending, ContainerStatuses: waiting("CreateContainerConfigError")},
+			wantReason: "CreateContainerConfigError",
+		},
+		{
+			name:       "InvalidImageName",
+			status:     corev1.PodStatus{Phase: corev1.PodPending, ContainerStatuses: waiting("InvalidImageName")},
+			wantReason: "InvalidImageName",
+		},
+		{
+			name: "Init container ErrImagePull",
+			status: corev1.PodStatus{Phase: corev1.PodPending, InitContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "init-config-reloader",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull"}},
+			}}},
+			wantReason: "ErrImagePull",
+		},
+		{
+			name:   "ContainerCreating",
+			status: corev1.PodStatus{Phase: corev1.PodPending, ContainerStatuses: waiting("ContainerCreating")},
+		},
+		{
+			name: "Unschedulable",
+			status: corev1.PodStatus{Phase: corev1.PodPending, Conditions: []corev1.PodCondition{{
+				Type:    corev1.PodScheduled,
+				Status:  corev1.ConditionFalse,
+				Reason:  corev1.PodReasonUnschedulable,
+				Message: "0/3 nodes are available: 3 node(s) had untolerated taint",
+			}}},
+			wantReason: "Unschedulable",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "configcheck-vector-abcde-xxxxx"}, Status: tc.status}
+			failure, retryable := configcheck.GetPodFailure(pod)
+			if tc.wantReason == "" {
+				require.Nil(t, failure)
+				return
+			}
+			require.NotNil(t, failure)
+			require.Equal(t, tc.wantReason, failure.Reason)
+			require.Equal(t, tc.wantRetryable, retryable)
+		})
+	}
+}
+
+func TestPodFailureError(t *testing.T) {
+	var err error = &configcheck.PodFailureError{Name: "configcheck-vector-abcde", Reason: "ImagePullBackOff", Message: "Back-off pulling image"}
+
+	require.True(t, errors.Is(err, configcheck.ConfigcheckInfrastructureError))
+	require.False(t, errors.Is(err, configcheck.ValidationError))
+	require.True(t, configcheck.IsResultUnknown(err))
+	require.True(t, configcheck.IsResultUnknown(configcheck.ConfigcheckTimeoutError))
+	require.False(t, configcheck.IsResultUnknown(configcheck.ValidationError))
+	require.Equal(t, vectorv1alpha1.ReasonConfigCheckInfrastructureFailure, configcheck.GetConditionReason(err))
+	require.Equal(t, "configcheck pod can't run: configcheck-vector-abcde: ImagePullBackOff: Back-off pulling image", err.Error())
+}
@@ -17,12 +17,13 @@ limitations under the License.
 package configcheck
 
 import (
+	"github.com/kaasops/vector-operator/controllers/factory/valueref"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func (cc *ConfigCheck) createVectorConfigCheckPod() *corev1.Pod {
-	labels := labelsForVectorConfigCheck()
+	labels := labelsForVectorConfigCheckInstance(cc.VectorName)
 	var initContainers []corev1.Container
 
 	if cc.CompressedConfig {
@@ -49,6 +50,7 @@ func (cc *ConfigCheck) createVectorConfigCheckPod() *corev1.Pod {
 					Resources:       cc.Resources,
 					Args:            []string{"validate", "/etc/vector/*.json"},
 					Env:             cc.generateVectorConfigCheckEnvs(),
+					EnvFrom:         cc.generateVectorConfigCheckEnvFrom(),
 					SecurityContext: cc.ContainerSecurityContext,
 					VolumeMounts:    cc.generateVectorConfigCheckVolumeMounts(),
 				},
@@ -171,6 +173,14 @@ func (cc *ConfigCheck) generateVectorConfigCheckVolumeMounts() []corev1.VolumeMo
 	return volumeMount
 }
 
+// generateVectorConfigCheckEnvFrom returns env source with referenced values
+func (cc *ConfigCheck) generateVectorConfigCheckEnvFrom() []corev1.EnvFromSource {
+	if len(cc.ValueRefs) == 0 {
+		return nil
+	}
+	return valueref.EnvFrom(cc.getNameVectorConfigCheckEnv())
+}
+
 func (cc *ConfigCheck) generateVectorConfigCheckEnvs() []corev1.EnvVar {
 	envs := cc.Envs
 
@@ -0,0 +1,126 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcheck
+
+import (
+	"context"
+	"time"
+
+	"github.com/kaasops/vector-operator/controllers/factory/metrics"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Janitor removes config check Jobs, pods and Secrets left after operator restart in the middle of config check.
+// Config check can't run longer than timeout, so resources older than timeout are orphaned
+type Janitor struct {
+	ClientSet kubernetes.Interface
+	// Namespace limits cleanup to one namespace, all namespaces are cleaned if it is empty
+	Namespace string
+	MaxAge    time.Duration
+	Interval  time.Duration
+}
+
+// Start runs cleanup on start and then every Interval until ctx is done. It implements manager.Runnable
+func (j *Janitor) Start(ctx context.Context) error {
+	log := log.FromContext(ctx).WithName("configcheck-janitor")
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := j.Sweep(ctx, time.Now()); err != nil {
+			log.Error(err, "Failed to remove orphaned config check resources")
+		}
+	}, j.Interval)
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, only leader runs config checks
+func (j *Janitor) NeedLeaderElection() bool {
+	return true
+}
+
+// Sweep removes config check Jobs, pods and Secrets created before now-MaxAge
+func (j *Janitor) Sweep(ctx context.Context, now time.Time) error {
+	log := log.FromContext(ctx).WithName("configcheck-janitor")
+	listOpts := metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labelsForVectorConfigCheck()).String(),
+	}
+	deadline := now.Add(-j.MaxAge)
+
+	jobs, err := j.ClientSet.BatchV1().Jobs(j.Namespace).List(ctx, listOpts)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs.Items {
+		if !job.CreationTimestamp.Time.Before(deadline) {
+			continue
+		}
+		if err := j.ClientSet.BatchV1().Jobs(job.Namespace).Delete(ctx, job.Name, deleteInBackground()); err != nil {
+			if api_errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		log.Info("Removed orphaned config check Job", "job", job.Namespace+"/"+job.Name)
+		metrics.ConfigCheckOrphanedResourcesRemoved.WithLabelValues("Job").Inc()
+	}
+
+	pods, err := j.ClientSet.CoreV1().Pods(j.Namespace).List(ctx, listOpts)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if !pod.CreationTimestamp.Time.Before(deadline) {
+			continue
+		}
+		if err := j.ClientSet.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil {
+			if api_errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		log.Info("Removed orphaned config check pod", "pod", pod.Namespace+"/"+pod.Name)
+		metrics.ConfigCheckOrphanedResourcesRemoved.WithLabelValues("Pod").Inc()
+	}
+
+	secrets, err := j.ClientSet.CoreV1().Secrets(j.Namespace).List(ctx, listOpts)
+	if err != nil {
+		return err
+	}
+	for _, secret := range secrets.Items {
+		if !secret.CreationTimestamp.Time.Before(deadline) {
+			continue
+		}
+		if err := j.ClientSet.CoreV1().Secrets(secret.Namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil {
+			if api_errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		log.Info("Removed orphaned config check Secret", "secret", secret.Namespace+"/"+secret.Name)
+		metrics.ConfigCheckOrphanedResourcesRemoved.WithLabelValues("Secret").Inc()
+	}
+	return nil
+}
+
+// deleteInBackground returns options to delete Job with its pods, Job pods are left without it
+func deleteInBackground() metav1.DeleteOptions {
+	propagation := metav1.DeletePropagationBackground
+	return metav1.DeleteOptions{PropagationPolicy: &propagation}
+}
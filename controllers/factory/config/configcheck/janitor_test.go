@@ -0,0 +1,90 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcheck_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kaasops/vector-operator/controllers/factory/config/configcheck"
+	"github.com/kaasops/vector-operator/controllers/factory/metrics"
+	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestJanitorSweep(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	configCheckLabels := map[string]string{
+		k8s.ManagedByLabelKey: "vector-operator",
+		k8s.NameLabelKey:      "vector-configcheck",
+		k8s.ComponentLabelKey: "ConfigCheck",
+		k8s.InstanceLabelKey:  "vector",
+	}
+	objectMeta := func(name string, age time.Duration, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "test",
+			Labels:            labels,
+			CreationTimestamp: metav1.NewTime(now.Add(-age)),
+		}
+	}
+
+	cs := fakeclientset.NewSimpleClientset(
+		&batchv1.Job{ObjectMeta: objectMeta("configcheck-vector-old", 10*time.Minute, configCheckLabels)},
+		&batchv1.Job{ObjectMeta: objectMeta("configcheck-vector-new", time.Minute, configCheckLabels)},
+		&corev1.Pod{ObjectMeta: objectMeta("configcheck-vector-old", 10*time.Minute, configCheckLabels)},
+		&corev1.Pod{ObjectMeta: objectMeta("configcheck-vector-new", time.Minute, configCheckLabels)},
+		&corev1.Pod{ObjectMeta: objectMeta("vector-agent-xxxxx", 10*time.Minute, map[string]string{k8s.ManagedByLabelKey: "vector-operator"})},
+		&corev1.Secret{ObjectMeta: objectMeta("configcheck-vector-old", 10*time.Minute, configCheckLabels)},
+		&corev1.Secret{ObjectMeta: objectMeta("configcheck-vector-old-env", 10*time.Minute, configCheckLabels)},
+		&corev1.Secret{ObjectMeta: objectMeta("configcheck-vector-new", time.Minute, configCheckLabels)},
+	)
+	jobsRemoved := testutil.ToFloat64(metrics.ConfigCheckOrphanedResourcesRemoved.WithLabelValues("Job"))
+	podsRemoved := testutil.ToFloat64(metrics.ConfigCheckOrphanedResourcesRemoved.WithLabelValues("Pod"))
+	secretsRemoved := testutil.ToFloat64(metrics.ConfigCheckOrphanedResourcesRemoved.WithLabelValues("Secret"))
+
+	j := &configcheck.Janitor{ClientSet: cs, MaxAge: 5 * time.Minute}
+	require.NoError(t, j.Sweep(context.Background(), now))
+
+	jobs, err := cs.BatchV1().Jobs("test").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, jobs.Items, 1)
+	require.Equal(t, "configcheck-vector-new", jobs.Items[0].Name)
+
+	pods, err := cs.CoreV1().Pods("test").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	var podNames []string
+	for _, pod := range pods.Items {
+		podNames = append(podNames, pod.Name)
+	}
+	require.ElementsMatch(t, []string{"configcheck-vector-new", "vector-agent-xxxxx"}, podNames)
+
+	secrets, err := cs.CoreV1().Secrets("test").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, secrets.Items, 1)
+	require.Equal(t, "configcheck-vector-new", secrets.Items[0].Name)
+
+	require.Equal(t, jobsRemoved+1, testutil.ToFloat64(metrics.ConfigCheckOrphanedResourcesRemoved.WithLabelValues("Job")))
+	require.Equal(t, podsRemoved+1, testutil.ToFloat64(metrics.ConfigCheckOrphanedResourcesRemoved.WithLabelValues("Pod")))
+	require.Equal(t, secretsRemoved+2, testutil.ToFloat64(metrics.ConfigCheckOrphanedResourcesRemoved.WithLabelValues("Secret")))
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcheck
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+)
+
+// Kinds of config errors, parsed from vector validate output
+const (
+	ConfigErrorKindComponent   = "Component"
+	ConfigErrorKindInput       = "Input"
+	ConfigErrorKindDuplicate   = "Duplicate"
+	ConfigErrorKindDataType    = "DataType"
+	ConfigErrorKindHealthCheck = "HealthCheck"
+	ConfigErrorKindConfig      = "Config"
+	ConfigErrorKindUnknown     = "Unknown"
+)
+
+const (
+	// MaxConfigErrors limits number of config errors saved to status
+	MaxConfigErrors = 10
+	// MaxConfigErrorMessageLength limits length of config error message saved to status
+	MaxConfigErrorMessageLength = 512
+)
+
+// ComponentResolver maps component name in generated config to pipeline ref and component name in pipeline.
+// Empty pipeline is returned for components, that don't belong to any pipeline
+type ComponentResolver func(name string) (pipeline, component string)
+
+var (
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+	configErrorPatterns = []struct {
+		re        *regexp.Regexp
+		kind      string
+		component int
+		message   int
+	}{
+		{regexp.MustCompile(`^(?:Source|Transform|Sink) "([^"]+)": ?(.*)$`), ConfigErrorKindComponent, 1, 2},
+		{regexp.MustCompile(`^Input "[^"]+" for (?:source|transform|sink) "([^"]+)" .*$`), ConfigErrorKindInput, 1, 0},
+		{regexp.MustCompile(`^(?:Source|Transform|Sink) "([^"]+)" has no inputs.*$`), ConfigErrorKindInput, 1, 0},
+		{regexp.MustCompile(`^More than one component with name "([^"]+)".*$`), ConfigErrorKindDuplicate, 1, 0},
+		{regexp.MustCompile(`^Data type mismatch between "[^"]+" .* and "([^"]+)".*$`), ConfigErrorKindDataType, 1, 0},
+		{regexp.MustCompile(`^Health check for "([^"]+)" failed: ?(.*)$`), ConfigErrorKindHealthCheck, 1, 2},
+		{regexp.MustCompile("^.* for key `(?:sources|transforms|sinks)\\.([^`.]+).*$"), ConfigErrorKindConfig, 1, 0},
+	}
+)
+
+// ParseValidateOutput parses errors from vector validate output. Errors are lines starting with "x",
+// multiline errors (e.g. VRL diagnostics) are shortened to the first non-empty line.
+// Component names are mapped back to pipelines with resolver, if it is not nil
+func ParseValidateOutput(output string, resolver ComponentResolver) []vectorv1alpha1.ConfigError {
+	var entries [][]string
+	for _, line := range strings.Split(ansiEscape.ReplaceAllString(output, ""), "\n") {
+		line = strings.TrimRight(line, " \r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "x "):
+			entries = append(entries, []string{strings.TrimSpace(strings.TrimPrefix(trimmed, "x "))})
+		case strings.HasPrefix(trimmed, "√ "), strings.HasPrefix(trimmed, "~ "), strings.HasPrefix(trimmed, "---"):
+			// Successful steps, warnings and separators end multiline error
+			if len(entries) != 0 {
+				entries = append(entries, nil)
+			}
+		default:
+			if len(entries) != 0 && entries[len(entries)-1] != nil && trimmed != "" {
+				entries[len(entries)-1] = append(entries[len(entries)-1], trimmed)
+			}
+		}
+	}
+
+	var configErrors []vectorv1alpha1.ConfigError
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		configError := parseConfigError(entry)
+		if configError.Component != "" && resolver != nil {
+			configError.Pipeline, configError.Component = resolver(configError.Component)
+		}
+		configErrors = append(configErrors, configError)
+		if len(configErrors) == MaxConfigErrors {
+			break
+		}
+	}
+	return configErrors
+}
+
+func parseConfigError(entry []string) vectorv1alpha1.ConfigError {
+	configError := vectorv1alpha1.ConfigError{
+		Kind:    ConfigErrorKindUnknown,
+		Message: entry[0],
+	}
+	for _, p := range configErrorPatterns {
+		m := p.re.FindStringSubmatch(entry[0])
+		if m == nil {
+			continue
+		}
+		configError.Kind = p.kind
+		configError.Component = m[p.component]
+		configError.Message = m[p.message]
+		break
+	}
+	if configError.Message == "" && len(entry) > 1 {
+		configError.Message = entry[1]
+	}
+	if len(configError.Message) > MaxConfigErrorMessageLength {
+		configError.Message = configError.Message[:MaxConfigErrorMessageLength-3] + "..."
+	}
+	return configError
+}
+
+// FormatConfigErrors returns concise status reason for config errors, one error per line
+func FormatConfigErrors(configErrors []vectorv1alpha1.ConfigError) string {
+	lines := make([]string, 0, len(configErrors))
+	for _, e := range configErrors {
+		var line string
+		switch {
+		case e.Pipeline != "":
+			line = fmt.Sprintf("pipeline %s, component %s: ", e.Pipeline, e.Component)
+		case e.Component != "":
+			line = fmt.Sprintf("component %s: ", e.Component)
+		}
+		lines = append(lines, fmt.Sprintf("%s%s (%s)", line, e.Message, e.Kind))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcheck_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/config/configcheck"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseValidateOutput(t *testing.T) {
+	resolver := func(name string) (string, string) {
+		if strings.HasPrefix(name, "ns-p-") {
+			return "ns/p", strings.TrimPrefix(name, "ns-p-")
+		}
+		return "", name
+	}
+
+	type testCase struct {
+		name     string
+		output   string
+		resolver configcheck.ComponentResolver
+		want     []vectorv1alpha1.ConfigError
+	}
+
+	cases := []testCase{
+		{
+			name: "Component errors",
+			output: "\x1b[31mx\x1b[0m Sink \"ns-p-sink\": unknown variant `foo`\n" +
+				"x Transform \"ns-p-remap\": \n" +
+				"error[E103]: unhandled fallible assignment\n" +
+				"  ┌─ :1:5\n" +
+				"  │\n" +
+				"1 │ . = parse_json(.message)\n",
+			resolver: resolver,
+			want: []vectorv1alpha1.ConfigError{
+				{Pipeline: "ns/p", Component: "sink", Kind: configcheck.ConfigErrorKindComponent, Message: "unknown variant `foo`"},
+				{Pipeline: "ns/p", Component: "remap", Kind: configcheck.ConfigErrorKindComponent, Message: "error[E103]: unhandled fallible assignment"},
+			},
+		},
+		{
+			name: "Topology and health check errors",
+			output: "√ Loaded [\"/etc/vector/agent.json\"]\n" +
+				"x Input \"ns-p-srcc\" for sink \"ns-p-sink\" doesn't match any components.\n" +
+				"x More than one component with name \"mergedKubernetesSource\" (source, transform).\n" +
+				"x Health check for \"ns-p-es\" failed: connection refused\n" +
+				"~ Health check disabled for \"ns-p-console\"\n",
+			resolver: resolver,
+			want: []vectorv1alpha1.ConfigError{
+				{Pipeline: "ns/p", Component: "sink", Kind: configcheck.ConfigErrorKindInput, Message: "Input \"ns-p-srcc\" for sink \"ns-p-sink\" doesn't match any components."},
+				{Component: "mergedKubernetesSource", Kind: configcheck.ConfigErrorKindDuplicate, Message: "More than one component with name \"mergedKubernetesSource\" (source, transform)."},
+				{Pipeline: "ns/p", Component: "es", Kind: configcheck.ConfigErrorKindHealthCheck, Message: "connection refused"},
+			},
+		},
+		{
+			name: "Config error without resolver",
+			output: "Failed to load [\"/etc/vector/agent.json\"]\n" +
+				"-----------------------------------------\n" +
+				"x missing field `inputs` for key `sinks.ns-p-sink` at line 1 column 120\n" +
+				"x unexpected end of file\n",
+			want: []vectorv1alpha1.ConfigError{
+				{Component: "ns-p-sink", Kind: configcheck.ConfigErrorKindConfig, Message: "missing field `inputs` for key `sinks.ns-p-sink` at line 1 column 120"},
+				{Kind: configcheck.ConfigErrorKindUnknown, Message: "unexpected end of file"},
+			},
+		},
+		{
+			name:   "Unknown output",
+			output: "thread 'main' panicked at 'out of memory'\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, configcheck.ParseValidateOutput(tc.output, tc.resolver))
+		})
+	}
+}
+
+func TestParseValidateOutputLimits(t *testing.T) {
+	var output strings.Builder
+	for i := 0; i < configcheck.MaxConfigErrors+5; i++ {
+		fmt.Fprintf(&output, "x Sink \"sink%d\": %s\n", i, strings.Repeat("a", configcheck.MaxConfigErrorMessageLength+1))
+	}
+
+	configErrors := configcheck.ParseValidateOutput(output.String(), nil)
+	require.Len(t, configErrors, configcheck.MaxConfigErrors)
+	require.Len(t, configErrors[0].Message, configcheck.MaxConfigErrorMessageLength)
+	require.True(t, strings.HasSuffix(configErrors[0].Message, "..."))
+}
+
+func TestFormatConfigErrors(t *testing.T) {
+	reason := configcheck.FormatConfigErrors([]vectorv1alpha1.ConfigError{
+		{Pipeline: "ns/p", Component: "sink", Kind: configcheck.ConfigErrorKindComponent, Message: "unknown variant `foo`"},
+		{Component: "mergedKubernetesSource", Kind: configcheck.ConfigErrorKindDuplicate, Message: "duplicate"},
+		{Kind: configcheck.ConfigErrorKindUnknown, Message: "unexpected end of file"},
+	})
+	require.Equal(t, "pipeline ns/p, component sink: unknown variant `foo` (Component)\n"+
+		"component mergedKubernetesSource: duplicate (Duplicate)\n"+
+		"unexpected end of file (Unknown)", reason)
+}
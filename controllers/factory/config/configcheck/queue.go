@@ -0,0 +1,181 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcheck
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kaasops/vector-operator/controllers/factory/metrics"
+)
+
+// Queue limits number of configcheck pods running at once. Waiting checks are started by priority:
+// Vector-initiated checks before pipeline checks, checks with the same priority in order of arrival
+type Queue struct {
+	maxInFlight int
+
+	mu       sync.Mutex
+	inFlight int
+	seq      uint64
+	waiting  waiters
+}
+
+type waiter struct {
+	initiator string
+	priority  int
+	seq       uint64
+	index     int
+	ready     chan struct{}
+}
+
+// NewQueue returns Queue with maxInFlight running checks. Checks are not limited, if maxInFlight is not positive
+func NewQueue(maxInFlight int) *Queue {
+	return &Queue{maxInFlight: maxInFlight}
+}
+
+func priority(initiator string) int {
+	if initiator == ConfigCheckInitiatorVector {
+		return 0
+	}
+	return 1
+}
+
+// Acquire blocks until check can be started, ctx is done or check waits longer than timeout. Timeout is not
+// applied, if it is not positive. ConfigcheckTimeoutError is returned on timeout, so config validity becomes
+// unknown. Release must be called after check is finished. Nil Queue doesn't limit checks
+func (q *Queue) Acquire(ctx context.Context, initiator string, timeout time.Duration) (release func(), err error) {
+	if q == nil {
+		return func() {}, nil
+	}
+
+	q.mu.Lock()
+	if q.maxInFlight <= 0 || (q.inFlight < q.maxInFlight && q.waiting.Len() == 0) {
+		q.inFlight++
+		q.updateMetrics()
+		q.mu.Unlock()
+		return q.releaseFunc(), nil
+	}
+	w := &waiter{
+		initiator: initiator,
+		priority:  priority(initiator),
+		seq:       q.seq,
+		ready:     make(chan struct{}),
+	}
+	q.seq++
+	heap.Push(&q.waiting, w)
+	q.updateMetrics()
+	q.mu.Unlock()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case <-w.ready:
+		return q.releaseFunc(), nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-expired:
+		err = fmt.Errorf("%w: no free configcheck slot in %s", ConfigcheckTimeoutError, timeout)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	select {
+	case <-w.ready:
+		// Slot was granted concurrently with cancel, so it is passed to the next waiter
+		q.inFlight--
+		q.startNext()
+	default:
+		heap.Remove(&q.waiting, w.index)
+	}
+	q.updateMetrics()
+	return nil, err
+}
+
+func (q *Queue) releaseFunc() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			q.inFlight--
+			q.startNext()
+			q.updateMetrics()
+		})
+	}
+}
+
+// startNext starts waiting checks while there are free slots. q.mu must be held
+func (q *Queue) startNext() {
+	for q.waiting.Len() != 0 && (q.maxInFlight <= 0 || q.inFlight < q.maxInFlight) {
+		w := heap.Pop(&q.waiting).(*waiter)
+		q.inFlight++
+		close(w.ready)
+	}
+}
+
+// updateMetrics records queue depth by initiator and running checks. q.mu must be held
+func (q *Queue) updateMetrics() {
+	depth := map[string]int{ConfigCheckInitiatorVector: 0, ConfigCheckInitiatorPipieline: 0}
+	for _, w := range q.waiting {
+		depth[w.initiator]++
+	}
+	for initiator, n := range depth {
+		metrics.ConfigCheckQueueDepth.WithLabelValues(initiator).Set(float64(n))
+	}
+	metrics.ConfigCheckInFlight.Set(float64(q.inFlight))
+}
+
+// waiters implements heap.Interface ordered by priority and arrival
+type waiters []*waiter
+
+func (w waiters) Len() int { return len(w) }
+
+func (w waiters) Less(i, j int) bool {
+	if w[i].priority != w[j].priority {
+		return w[i].priority < w[j].priority
+	}
+	return w[i].seq < w[j].seq
+}
+
+func (w waiters) Swap(i, j int) {
+	w[i], w[j] = w[j], w[i]
+	w[i].index = i
+	w[j].index = j
+}
+
+func (w *waiters) Push(x interface{}) {
+	item := x.(*waiter)
+	item.index = len(*w)
+	*w = append(*w, item)
+}
+
+func (w *waiters) Pop() interface{} {
+	old := *w
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*w = old[:n-1]
+	return item
+}
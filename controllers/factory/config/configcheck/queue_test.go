@@ -0,0 +1,110 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcheck_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kaasops/vector-operator/controllers/factory/config/configcheck"
+	"github.com/kaasops/vector-operator/controllers/factory/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueuePriority(t *testing.T) {
+	ctx := context.Background()
+	q := configcheck.NewQueue(1)
+
+	release, err := q.Acquire(ctx, configcheck.ConfigCheckInitiatorPipieline, 0)
+	require.NoError(t, err)
+
+	started := make(chan string, 3)
+	acquire := func(name, initiator string) {
+		release, err := q.Acquire(ctx, initiator, 0)
+		if err != nil {
+			started <- err.Error()
+			return
+		}
+		started <- name
+		release()
+	}
+	go acquire("pipeline1", configcheck.ConfigCheckInitiatorPipieline)
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.ConfigCheckQueueDepth.WithLabelValues(configcheck.ConfigCheckInitiatorPipieline)) == 1
+	}, time.Second, 10*time.Millisecond)
+	go acquire("pipeline2", configcheck.ConfigCheckInitiatorPipieline)
+	go acquire("vector", configcheck.ConfigCheckInitiatorVector)
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.ConfigCheckQueueDepth.WithLabelValues(configcheck.ConfigCheckInitiatorPipieline)) == 2 &&
+			testutil.ToFloat64(metrics.ConfigCheckQueueDepth.WithLabelValues(configcheck.ConfigCheckInitiatorVector)) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.ConfigCheckInFlight))
+
+	release()
+	require.Equal(t, []string{"vector", "pipeline1", "pipeline2"}, []string{<-started, <-started, <-started})
+	require.Equal(t, float64(0), testutil.ToFloat64(metrics.ConfigCheckInFlight))
+	require.Equal(t, float64(0), testutil.ToFloat64(metrics.ConfigCheckQueueDepth.WithLabelValues(configcheck.ConfigCheckInitiatorPipieline)))
+}
+
+func TestQueueCancel(t *testing.T) {
+	q := configcheck.NewQueue(1)
+	release, err := q.Acquire(context.Background(), configcheck.ConfigCheckInitiatorVector, 0)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = q.Acquire(ctx, configcheck.ConfigCheckInitiatorPipieline, 0)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, float64(0), testutil.ToFloat64(metrics.ConfigCheckQueueDepth.WithLabelValues(configcheck.ConfigCheckInitiatorPipieline)))
+
+	release()
+	release, err = q.Acquire(context.Background(), configcheck.ConfigCheckInitiatorPipieline, 0)
+	require.NoError(t, err)
+	release()
+}
+
+func TestQueueTimeout(t *testing.T) {
+	q := configcheck.NewQueue(1)
+	release, err := q.Acquire(context.Background(), configcheck.ConfigCheckInitiatorVector, 0)
+	require.NoError(t, err)
+
+	_, err = q.Acquire(context.Background(), configcheck.ConfigCheckInitiatorPipieline, 50*time.Millisecond)
+	require.ErrorIs(t, err, configcheck.ConfigcheckTimeoutError)
+	require.True(t, configcheck.IsResultUnknown(err))
+	require.Equal(t, float64(0), testutil.ToFloat64(metrics.ConfigCheckQueueDepth.WithLabelValues(configcheck.ConfigCheckInitiatorPipieline)))
+
+	release()
+	release, err = q.Acquire(context.Background(), configcheck.ConfigCheckInitiatorPipieline, 50*time.Millisecond)
+	require.NoError(t, err)
+	release()
+}
+
+func TestQueueUnlimited(t *testing.T) {
+	for _, q := range []*configcheck.Queue{nil, configcheck.NewQueue(0)} {
+		var releases []func()
+		for i := 0; i < 10; i++ {
+			release, err := q.Acquire(context.Background(), configcheck.ConfigCheckInitiatorPipieline, 0)
+			require.NoError(t, err)
+			releases = append(releases, release)
+		}
+		for _, release := range releases {
+			release()
+		}
+	}
+}
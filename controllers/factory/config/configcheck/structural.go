@@ -0,0 +1,214 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kaasops/vector-operator/controllers/factory/config"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	sourcesKey    = "sources"
+	transformsKey = "transforms"
+	sinksKey      = "sinks"
+)
+
+// StructuralValidator validates Vector config in operator process, without configcheck pod.
+// It checks duplicate component names, unknown component types, transforms and sinks without inputs.
+// Dangling inputs and cycles between transforms are found with VectorConfig.CheckTopology, that is run on config build
+type StructuralValidator struct {
+	// AllowUnknownTypes only logs component types unknown to operator, e.g. added in Vector image newer than operator.
+	// They are checked by configcheck pod then
+	AllowUnknownTypes bool
+}
+
+func NewStructuralValidator(allowUnknownTypes bool) *StructuralValidator {
+	return &StructuralValidator{AllowUnknownTypes: allowUnknownTypes}
+}
+
+type component struct {
+	Type   string   `json:"type"`
+	Inputs []string `json:"inputs"`
+}
+
+type graph struct {
+	sources    map[string]component
+	transforms map[string]component
+	sinks      map[string]component
+}
+
+func (sv *StructuralValidator) Validate(ctx context.Context, cfgData []byte) (string, error) {
+	var cfg map[string]json.RawMessage
+	if err := json.Unmarshal(cfgData, &cfg); err != nil {
+		return err.Error(), ValidationError
+	}
+
+	var errs, unknownTypes []string
+	g := graph{}
+	for _, section := range []struct {
+		key        string
+		components *map[string]component
+		types      map[string]struct{}
+	}{
+		{sourcesKey, &g.sources, SourceTypes},
+		{transformsKey, &g.transforms, TransformTypes},
+		{sinksKey, &g.sinks, SinkTypes},
+	} {
+		components, sectionErrs, err := parseSection(cfg[section.key], section.key)
+		if err != nil {
+			return err.Error(), ValidationError
+		}
+		*section.components = components
+		errs = append(errs, sectionErrs...)
+		unknownTypes = append(unknownTypes, checkTypes(components, section.key, section.types)...)
+	}
+
+	if sv.AllowUnknownTypes {
+		if len(unknownTypes) != 0 {
+			log.FromContext(ctx).Info("Config has component types unknown to operator, they are checked by configcheck pod", "types", strings.Join(unknownTypes, "; "))
+		}
+	} else {
+		errs = append(errs, unknownTypes...)
+	}
+
+	errs = append(errs, g.checkDuplicates()...)
+	errs = append(errs, g.checkInputs()...)
+	errs = append(errs, g.checkTopology()...)
+
+	if len(errs) != 0 {
+		return strings.Join(errs, "\n"), ValidationError
+	}
+	return "", nil
+}
+
+// parseSection decodes components of config section and returns errors for duplicate names.
+// Duplicate keys are lost by json.Unmarshal, so they are found with json.Decoder
+func parseSection(raw json.RawMessage, key string) (map[string]component, []string, error) {
+	components := map[string]component{}
+	if len(raw) == 0 || string(raw) == "null" {
+		return components, nil, nil
+	}
+
+	var errs []string
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, err
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		name, ok := t.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s: unexpected token %v", key, t)
+		}
+		var c component
+		if err := dec.Decode(&c); err != nil {
+			return nil, nil, fmt.Errorf("%s %q: %w", key, name, err)
+		}
+		if _, ok := components[name]; ok {
+			errs = append(errs, fmt.Sprintf("duplicate component name %q in %s", name, key))
+		}
+		components[name] = c
+	}
+	return components, errs, nil
+}
+
+// checkTypes returns components with types, that are not known by operator
+func checkTypes(components map[string]component, key string, types map[string]struct{}) (unknown []string) {
+	for _, name := range sortedNames(components) {
+		if _, ok := types[components[name].Type]; !ok {
+			unknown = append(unknown, fmt.Sprintf("%s %q: unknown component type %q", key, name, components[name].Type))
+		}
+	}
+	return unknown
+}
+
+func (g graph) checkDuplicates() (errs []string) {
+	for _, name := range sortedNames(g.transforms) {
+		if _, ok := g.sources[name]; ok {
+			errs = append(errs, fmt.Sprintf("duplicate component name %q in sources and transforms", name))
+		}
+	}
+	for _, name := range sortedNames(g.sinks) {
+		if _, ok := g.sources[name]; ok {
+			errs = append(errs, fmt.Sprintf("duplicate component name %q in sources and sinks", name))
+		}
+		if _, ok := g.transforms[name]; ok {
+			errs = append(errs, fmt.Sprintf("duplicate component name %q in transforms and sinks", name))
+		}
+	}
+	return errs
+}
+
+func (g graph) checkInputs() (errs []string) {
+	for _, section := range []struct {
+		key        string
+		components map[string]component
+	}{
+		{transformsKey, g.transforms},
+		{sinksKey, g.sinks},
+	} {
+		for _, name := range sortedNames(section.components) {
+			if len(section.components[name].Inputs) == 0 {
+				errs = append(errs, fmt.Sprintf("%s %q: no inputs", section.key, name))
+			}
+		}
+	}
+	return errs
+}
+
+// checkTopology returns dangling inputs, sources without consumers and cycles between transforms
+func (g graph) checkTopology() (errs []string) {
+	vectorConfig := &config.VectorConfig{}
+	for _, name := range sortedNames(g.sources) {
+		vectorConfig.Sources = append(vectorConfig.Sources, &config.Source{Name: name, Type: g.sources[name].Type})
+	}
+	for _, name := range sortedNames(g.transforms) {
+		c := g.transforms[name]
+		vectorConfig.Transforms = append(vectorConfig.Transforms, &config.Transform{Name: name, Type: c.Type, Inputs: c.Inputs})
+	}
+	for _, name := range sortedNames(g.sinks) {
+		c := g.sinks[name]
+		vectorConfig.Sinks = append(vectorConfig.Sinks, &config.Sink{Name: name, Type: c.Type, Inputs: c.Inputs})
+	}
+	var topologyErrs config.TopologyErrors
+	if errors.As(vectorConfig.CheckTopology(), &topologyErrs) {
+		for _, err := range topologyErrs {
+			errs = append(errs, err.Error())
+		}
+	}
+	return errs
+}
+
+func sortedNames(components map[string]component) []string {
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
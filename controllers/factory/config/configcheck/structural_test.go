@@ -0,0 +1,148 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcheck_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kaasops/vector-operator/controllers/factory/config/configcheck"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuralValidator(t *testing.T) {
+	type testCase struct {
+		name              string
+		config            string
+		allowUnknownTypes bool
+		wantReason        string
+	}
+
+	cases := []testCase{
+		{
+			name: "Valid config",
+			config: `{
+				"sources": {"src": {"type": "kubernetes_logs"}},
+				"transforms": {
+					"route": {"type": "route", "inputs": ["src"], "route": {"a": "true"}},
+					"remap": {"type": "remap", "inputs": ["route.a", "route._unmatched"]}
+				},
+				"sinks": {"sink": {"type": "console", "inputs": ["remap", "sr*"]}}
+			}`,
+		},
+		{
+			name: "Component name with dots",
+			config: `{
+				"sources": {"ns-p.1-src": {"type": "kubernetes_logs"}},
+				"transforms": {"ns-p.1-route": {"type": "route", "inputs": ["ns-p.1-src"]}},
+				"sinks": {"sink": {"type": "console", "inputs": ["ns-p.1-route.a"]}}
+			}`,
+		},
+		{
+			name: "Dangling input",
+			config: `{
+				"sources": {"src": {"type": "kubernetes_logs"}},
+				"sinks": {"sink": {"type": "console", "inputs": ["src", "missing"]}}
+			}`,
+			wantReason: `sink: input "missing" doesn't match any source or transform`,
+		},
+		{
+			name: "Sink as input",
+			config: `{
+				"sources": {"src": {"type": "kubernetes_logs"}},
+				"sinks": {
+					"sink1": {"type": "console", "inputs": ["src"]},
+					"sink2": {"type": "console", "inputs": ["sink1"]}
+				}
+			}`,
+			wantReason: `sink2: input "sink1" doesn't match any source or transform`,
+		},
+		{
+			name: "Unknown type",
+			config: `{
+				"sources": {"src": {"type": "kubernetes_log"}},
+				"sinks": {"sink": {"type": "console", "inputs": ["src"]}}
+			}`,
+			wantReason: `sources "src": unknown component type "kubernetes_log"`,
+		},
+		{
+			name: "Unknown type allowed",
+			config: `{
+				"sources": {"src": {"type": "kubernetes_log"}},
+				"sinks": {"sink": {"type": "console", "inputs": ["src"]}}
+			}`,
+			allowUnknownTypes: true,
+		},
+		{
+			name: "No inputs",
+			config: `{
+				"sources": {"src": {"type": "kubernetes_logs"}},
+				"transforms": {"remap": {"type": "remap"}},
+				"sinks": {"sink": {"type": "console", "inputs": ["src"]}}
+			}`,
+			wantReason: `transforms "remap": no inputs`,
+		},
+		{
+			name: "Duplicate name in one section",
+			config: `{
+				"sources": {"src": {"type": "kubernetes_logs"}, "src": {"type": "file"}},
+				"sinks": {"sink": {"type": "console", "inputs": ["src"]}}
+			}`,
+			wantReason: `duplicate component name "src" in sources`,
+		},
+		{
+			name: "Duplicate name in different sections",
+			config: `{
+				"sources": {"src": {"type": "kubernetes_logs"}},
+				"transforms": {"src": {"type": "remap", "inputs": ["src"]}},
+				"sinks": {"sink": {"type": "console", "inputs": ["src"]}}
+			}`,
+			wantReason: `duplicate component name "src" in sources and transforms`,
+		},
+		{
+			name: "Cycle",
+			config: `{
+				"sources": {"src": {"type": "kubernetes_logs"}},
+				"transforms": {
+					"a": {"type": "remap", "inputs": ["src", "c"]},
+					"b": {"type": "remap", "inputs": ["a"]},
+					"c": {"type": "route", "inputs": ["b"]}
+				},
+				"sinks": {"sink": {"type": "console", "inputs": ["c.x"]}}
+			}`,
+			wantReason: `cycle in transforms: a -> c -> b -> a`,
+		},
+		{
+			name:       "Invalid json",
+			config:     `{"sources": [}`,
+			wantReason: "invalid character",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reason, err := configcheck.NewStructuralValidator(tc.allowUnknownTypes).Validate(context.Background(), []byte(tc.config))
+			if tc.wantReason == "" {
+				require.NoError(t, err)
+				require.Empty(t, reason)
+				return
+			}
+			require.ErrorIs(t, err, configcheck.ValidationError)
+			require.Contains(t, reason, tc.wantReason)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcheck
+
+// Component types known by StructuralValidator. Deprecated aliases are kept, so configs for older
+// Vector versions are not rejected. Add new types here, when default Vector version is updated.
+var (
+	SourceTypes = typeSet(
+		"amqp",
+		"apache_metrics",
+		"aws_ecs_metrics",
+		"aws_kinesis_firehose",
+		"aws_s3",
+		"aws_sqs",
+		"datadog_agent",
+		"demo_logs",
+		"dnstap",
+		"docker_logs",
+		"eventstoredb_metrics",
+		"exec",
+		"file",
+		"file_descriptor",
+		"fluent",
+		"gcp_pubsub",
+		"generator",
+		"heroku_logs",
+		"host_metrics",
+		"http",
+		"http_client",
+		"http_server",
+		"internal_logs",
+		"internal_metrics",
+		"journald",
+		"kafka",
+		"kubernetes_logs",
+		"logplex",
+		"logstash",
+		"mongodb_metrics",
+		"nats",
+		"nginx_metrics",
+		"opentelemetry",
+		"postgresql_metrics",
+		"prometheus",
+		"prometheus_remote_write",
+		"prometheus_scrape",
+		"redis",
+		"socket",
+		"splunk_hec",
+		"statsd",
+		"stdin",
+		"syslog",
+		"vector",
+	)
+
+	TransformTypes = typeSet(
+		"add_fields",
+		"add_tags",
+		"aggregate",
+		"aws_ec2_metadata",
+		"dedupe",
+		"filter",
+		"geoip",
+		"log_to_metric",
+		"lua",
+		"metric_to_log",
+		"pipelines",
+		"reduce",
+		"remap",
+		"remove_fields",
+		"remove_tags",
+		"route",
+		"sample",
+		"swimlanes",
+		"tag_cardinality_limit",
+		"throttle",
+	)
+
+	SinkTypes = typeSet(
+		"amqp",
+		"appsignal",
+		"aws_cloudwatch_logs",
+		"aws_cloudwatch_metrics",
+		"aws_kinesis_firehose",
+		"aws_kinesis_streams",
+		"aws_s3",
+		"aws_sqs",
+		"axiom",
+		"azure_blob",
+		"azure_monitor_logs",
+		"blackhole",
+		"clickhouse",
+		"console",
+		"databend",
+		"datadog_events",
+		"datadog_logs",
+		"datadog_metrics",
+		"datadog_traces",
+		"elasticsearch",
+		"file",
+		"gcp_chronicle_unstructured",
+		"gcp_cloud_storage",
+		"gcp_pubsub",
+		"gcp_stackdriver_logs",
+		"gcp_stackdriver_metrics",
+		"honeycomb",
+		"http",
+		"humio_logs",
+		"humio_metrics",
+		"influxdb_logs",
+		"influxdb_metrics",
+		"kafka",
+		"logdna",
+		"loki",
+		"mezmo",
+		"nats",
+		"new_relic",
+		"new_relic_logs",
+		"papertrail",
+		"prometheus",
+		"prometheus_exporter",
+		"prometheus_remote_write",
+		"pulsar",
+		"redis",
+		"sematext_logs",
+		"sematext_metrics",
+		"socket",
+		"splunk_hec",
+		"splunk_hec_logs",
+		"splunk_hec_metrics",
+		"statsd",
+		"vector",
+		"websocket",
+	)
+)
+
+func typeSet(types ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(types))
+	for _, t := range types {
+		set[t] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configcheck
+
+import (
+	"context"
+)
+
+// Validator validates Vector config.
+// If config is invalid, Validate returns reason and ValidationError
+type Validator interface {
+	Validate(ctx context.Context, config []byte) (string, error)
+}
+
+// Validators runs validators in order and stops on the first failed validator
+type Validators []Validator
+
+func (vs Validators) Validate(ctx context.Context, config []byte) (string, error) {
+	for _, v := range vs {
+		reason, err := v.Validate(ctx, config)
+		if err != nil {
+			return reason, err
+		}
+	}
+	return "", nil
+}
+
+// Validate implements Validator with vector validate running in configcheck pod
+func (cc *ConfigCheck) Validate(ctx context.Context, config []byte) (string, error) {
+	cc.Config = config
+	return cc.Run(ctx)
+}
+
+// WithStructural returns validators, that run in-process structural validation before configcheck pod
+func (cc *ConfigCheck) WithStructural(allowUnknownTypes bool) Validators {
+	return Validators{NewStructuralValidator(allowUnknownTypes), cc}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/pipeline"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// SelfEnvPrefix is prefix of env variables with pod metadata, they are allowed for all pipelines
+const SelfEnvPrefix = "VECTOR_SELF_"
+
+// envReferenceRegexp matches Vector env interpolation: $VAR, ${VAR} and ${VAR:-default}. $$ is escaped $
+var envReferenceRegexp = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)[^}]*\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// vrlEnvFunctionRegexp matches VRL function, that reads env variables of Vector pods at runtime
+var vrlEnvFunctionRegexp = regexp.MustCompile(`\bget_env_var\b`)
+
+// checkVRLEnvAccess returns error, if transform of VectorPipeline uses VRL get_env_var, while Vector envAllowlist is set.
+// Variable name can be built at runtime, so it can't be checked by allowlist.
+// Options and routes are checked, VRL is used in remap source and in conditions of filter, route and other transforms
+func checkVRLEnvAccess(p pipeline.Pipeline, v *vectorv1alpha1.Vector, transforms []*Transform) error {
+	if p.GetNamespace() == "" || len(v.Spec.EnvAllowlist) == 0 {
+		return nil
+	}
+	for _, transform := range transforms {
+		data, err := json.Marshal([]interface{}{transform.Options, transform.Route})
+		if err != nil {
+			return err
+		}
+		if vrlEnvFunctionRegexp.Match(data) {
+			return fmt.Errorf("%s: %w: VRL get_env_var is not allowed in namespace %s, use secretRef or configMapRef", transform.Name, PipelineEnvError, p.GetNamespace())
+		}
+	}
+	return nil
+}
+
+// checkEnvAccess returns error, if VectorPipeline uses env variables not allowed for its namespace
+// by Vector envAllowlist
+func checkEnvAccess(p pipeline.Pipeline, v *vectorv1alpha1.Vector) error {
+	if p.GetNamespace() == "" || len(v.Spec.EnvAllowlist) == 0 {
+		return nil
+	}
+	var denied []string
+	for _, name := range getEnvReferences(p) {
+		if !isEnvAllowed(v.Spec.EnvAllowlist, p.GetNamespace(), name) {
+			denied = append(denied, name)
+		}
+	}
+	if len(denied) != 0 {
+		return fmt.Errorf("%w: %s not allowed in namespace %s", PipelineEnvError, strings.Join(denied, ", "), p.GetNamespace())
+	}
+	return nil
+}
+
+// getEnvReferences returns sorted names of env variables interpolated in pipeline components.
+// Vector interpolates whole config file, so all keys and values of pipeline spec are checked.
+// Env variables read with VRL get_env_var are not returned, VectorPipeline transforms can't use it with allowlist (checkVRLEnvAccess)
+func getEnvReferences(p pipeline.Pipeline) []string {
+	spec := p.GetSpec()
+	seen := make(map[string]bool)
+	typed, _ := json.Marshal([]interface{}{spec.TypedSources, spec.TypedTransforms, spec.TypedSinks})
+	for _, raw := range [][]byte{normalizeRaw(spec.Sources), normalizeRaw(spec.Transforms), normalizeRaw(spec.Sinks), typed} {
+		for _, m := range envReferenceRegexp.FindAllSubmatch(raw, -1) {
+			name := string(m[1]) + string(m[2])
+			if name != "" {
+				seen[name] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// normalizeRaw returns JSON without escape sequences like \u0024, that are decoded in generated config
+func normalizeRaw(raw *runtime.RawExtension) []byte {
+	if raw == nil {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw.Raw, &v); err != nil {
+		return raw.Raw
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return raw.Raw
+	}
+	return data
+}
+
+func isEnvAllowed(rules []vectorv1alpha1.EnvAllowlistRule, namespace, name string) bool {
+	if strings.HasPrefix(name, SelfEnvPrefix) {
+		return true
+	}
+	for _, rule := range rules {
+		if len(rule.Namespaces) != 0 && !contains(rule.Namespaces, namespace) {
+			continue
+		}
+		for _, pattern := range rule.Env {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/config"
+	"github.com/kaasops/vector-operator/controllers/factory/pipeline"
+	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoragent"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestBuilderEnvAllowlist(t *testing.T) {
+	allowlist := []vectorv1alpha1.EnvAllowlistRule{
+		{Env: []string{"COMMON_TOKEN"}},
+		{Namespaces: []string{"test"}, Env: []string{"ELASTIC_*"}},
+	}
+	sink := func(password string) *runtime.RawExtension {
+		return &runtime.RawExtension{
+			Raw: []byte(`{"sink1":{"type":"http","inputs":["source1"],"uri":"http://example.com","encoding":{"codec":"json"},"auth":{"strategy":"basic","user":"vector","password":"` + password + `"}}}`),
+		}
+	}
+
+	type testCase struct {
+		name      string
+		allowlist []vectorv1alpha1.EnvAllowlistRule
+		namespace string
+		password  string
+		wantErr   string
+	}
+
+	cases := []testCase{
+		{
+			name:     "No allowlist",
+			password: "${ANY_PASSWORD}",
+		},
+		{
+			name:      "Allowed for all namespaces",
+			allowlist: allowlist,
+			password:  "$COMMON_TOKEN",
+		},
+		{
+			name:      "Allowed by pattern",
+			allowlist: allowlist,
+			password:  "${ELASTIC_PASSWORD:-default}",
+		},
+		{
+			name:      "Pod metadata",
+			allowlist: allowlist,
+			password:  "${VECTOR_SELF_NODE_NAME}",
+		},
+		{
+			name:      "Escaped dollar",
+			allowlist: allowlist,
+			password:  "$$OTHER_PASSWORD",
+		},
+		{
+			name:      "Not allowed",
+			allowlist: allowlist,
+			password:  "${OTHER_PASSWORD}",
+			wantErr:   "OTHER_PASSWORD not allowed in namespace test",
+		},
+		{
+			name:      "Not allowed in namespace",
+			allowlist: allowlist,
+			namespace: "other",
+			password:  "${ELASTIC_PASSWORD}",
+			wantErr:   "ELASTIC_PASSWORD not allowed in namespace other",
+		},
+		{
+			name:      "Escaped in json",
+			allowlist: allowlist,
+			password:  `\u0024{OTHER_PASSWORD}`,
+			wantErr:   "OTHER_PASSWORD not allowed",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := newTestVector(false)
+			v.Spec.EnvAllowlist = tc.allowlist
+			p := newTestPipeline("p1", "")
+			if tc.namespace != "" {
+				p.Namespace = tc.namespace
+				p.Spec.Sources = &runtime.RawExtension{
+					Raw: []byte(`{"source1":{"type":"kubernetes_logs","extra_namespace_label_selector":"kubernetes.io/metadata.name=` + tc.namespace + `"}}`),
+				}
+			}
+			p.Spec.Sinks = sink(tc.password)
+
+			_, err := config.NewBuilder(vectoragent.NewController(v, nil, nil), p).GetByteConfig()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, config.PipelineEnvError)
+			require.Contains(t, err.Error(), tc.wantErr)
+			require.Equal(t, vectorv1alpha1.ReasonEnvNotAllowed, config.GetConditionReason(err))
+		})
+	}
+}
+
+func TestBuilderEnvAllowlistTypedComponents(t *testing.T) {
+	v := newTestVector(false)
+	v.Spec.EnvAllowlist = []vectorv1alpha1.EnvAllowlistRule{{Env: []string{"COMMON_TOKEN"}}}
+	p := newTestPipeline("p1", "")
+	p.Spec.Sinks = nil
+	p.Spec.TypedSinks = map[string]vectorv1alpha1.SinkSpec{
+		"sink1": {Inputs: []string{"source1"}, HTTP: &vectorv1alpha1.HTTPSink{
+			URI:      "http://example.com",
+			Encoding: vectorv1alpha1.SinkEncoding{Codec: "json"},
+			Auth:     &vectorv1alpha1.SinkAuth{Strategy: "bearer", Token: &apiextensionsv1.JSON{Raw: []byte(`"${ELASTIC_TOKEN}"`)}},
+		}},
+	}
+
+	_, err := config.NewBuilder(vectoragent.NewController(v, nil, nil), p).GetByteConfig()
+	require.ErrorIs(t, err, config.PipelineEnvError)
+	require.Contains(t, err.Error(), "ELASTIC_TOKEN")
+}
+
+func TestBuilderEnvAllowlistVRL(t *testing.T) {
+	v := newTestVector(false)
+	v.Spec.EnvAllowlist = []vectorv1alpha1.EnvAllowlistRule{{Env: []string{"COMMON_TOKEN"}}}
+	p := newTestPipeline("p1", "")
+	p.Spec.Transforms = &runtime.RawExtension{Raw: []byte(`{"t":{"type":"remap","inputs":["source1"],"source":".token = get_env_var!(\"ANY_VAR\")"}}`)}
+	p.Spec.Sinks = &runtime.RawExtension{Raw: []byte(`{"sink1":{"type":"console","inputs":["t"],"encoding":{"codec":"json"}}}`)}
+
+	_, err := config.NewBuilder(vectoragent.NewController(v, nil, nil), p).GetByteConfig()
+	require.ErrorIs(t, err, config.PipelineEnvError)
+	require.Equal(t, vectorv1alpha1.ReasonEnvNotAllowed, config.GetConditionReason(err))
+}
+
+func TestBuilderEnvAllowlistPipelineError(t *testing.T) {
+	v := newTestVector(false)
+	v.Spec.EnvAllowlist = []vectorv1alpha1.EnvAllowlistRule{{Env: []string{"COMMON_TOKEN"}}}
+	denied := newTestPipeline("denied", "")
+	denied.Spec.Sinks = &runtime.RawExtension{
+		Raw: []byte(`{"sink1":{"type":"console","inputs":["source1"],"encoding":{"codec":"json"},"target":"${OTHER_TARGET}"}}`),
+	}
+
+	_, err := config.NewBuilder(vectoragent.NewController(v, nil, nil), newTestPipeline("p1", ""), denied).GetByteConfig()
+	require.ErrorIs(t, err, config.PipelineEnvError)
+	pipelineErrors := config.GetPipelineErrors(err)
+	require.Len(t, pipelineErrors, 1)
+	require.ErrorIs(t, pipelineErrors["test/denied"], config.PipelineEnvError)
+}
+
+func TestBuilderEnvAllowlistClusterPipeline(t *testing.T) {
+	v := newTestVector(false)
+	v.Spec.EnvAllowlist = []vectorv1alpha1.EnvAllowlistRule{{Env: []string{"COMMON_TOKEN"}}}
+	p := &vectorv1alpha1.ClusterVectorPipeline{
+		TypeMeta:   metav1.TypeMeta{Kind: vectorv1alpha1.ClusterPipelineKind},
+		ObjectMeta: metav1.ObjectMeta{Name: "cp1"},
+		Spec: vectorv1alpha1.VectorPipelineSpec{
+			Sources: &runtime.RawExtension{Raw: []byte(`{"source1":{"type":"file","include":["/var/log/${LOG_DIR}/*.log"]}}`)},
+			Sinks:   &runtime.RawExtension{Raw: []byte(`{"sink1":{"type":"console","inputs":["source1"],"encoding":{"codec":"json"}}}`)},
+		},
+	}
+
+	_, err := config.NewBuilder(vectoragent.NewController(v, nil, nil), []pipeline.Pipeline{p}...).GetByteConfig()
+	require.NoError(t, err)
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/pipeline"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TemplateTransformType is type of pipeline transform, that is expanded to transforms of VectorTransformTemplate
+const TemplateTransformType = "template"
+
+// templateParameterRegexp matches template parameter $(name)
+var templateParameterRegexp = regexp.MustCompile(`\$\(([A-Za-z_][A-Za-z0-9_]*)\)`)
+
+// Templates are VectorTransformTemplates by name
+type Templates map[string]*vectorv1alpha1.VectorTransformTemplate
+
+// GetTemplates returns all VectorTransformTemplates
+func GetTemplates(ctx context.Context, c client.Client) (Templates, error) {
+	list := &vectorv1alpha1.VectorTransformTemplateList{}
+	if err := c.List(ctx, list); err != nil {
+		return nil, err
+	}
+	templates := make(Templates, len(list.Items))
+	for i := range list.Items {
+		templates[list.Items[i].Name] = &list.Items[i]
+	}
+	return templates, nil
+}
+
+// ReferencesTemplate returns true, if pipeline has template transform with VectorTransformTemplate name
+func ReferencesTemplate(p pipeline.Pipeline, name string) bool {
+	transforms, err := decodeComponents(p.GetSpec().Transforms, p.GetSpec().TypedTransforms)
+	if err != nil {
+		return false
+	}
+	for _, v := range transforms {
+		component, _ := v.(map[string]interface{})
+		if component["type"] == TemplateTransformType && component["name"] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// expandTemplates replaces template transforms with transforms of referenced VectorTransformTemplates.
+// Template output transform gets name of template transform, so pipeline components use it as input as is,
+// other template transforms are named <template transform>-<transform>
+func expandTemplates(transforms map[string]interface{}, templates Templates) (map[string]interface{}, error) {
+	expanded := make(map[string]interface{}, len(transforms))
+	for name, v := range transforms {
+		component, _ := v.(map[string]interface{})
+		if component["type"] != TemplateTransformType {
+			if _, ok := expanded[name]; ok {
+				return nil, fmt.Errorf("%w: %s: transform name is used by expanded template", PipelineTemplateError, name)
+			}
+			expanded[name] = v
+			continue
+		}
+		templateTransforms, err := expandTemplate(name, component, templates)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %s", PipelineTemplateError, name, err)
+		}
+		for expandedName, transform := range templateTransforms {
+			_, exists := transforms[expandedName]
+			if _, ok := expanded[expandedName]; ok || (exists && expandedName != name) {
+				return nil, fmt.Errorf("%w: %s: expanded transform %s clashes with pipeline transform", PipelineTemplateError, name, expandedName)
+			}
+			expanded[expandedName] = transform
+		}
+	}
+	return expanded, nil
+}
+
+func expandTemplate(name string, component map[string]interface{}, templates Templates) (map[string]interface{}, error) {
+	templateName, _ := component["name"].(string)
+	template, ok := templates[templateName]
+	if !ok {
+		return nil, fmt.Errorf("VectorTransformTemplate %q not found", templateName)
+	}
+	if template.Spec.Transforms == nil {
+		return nil, fmt.Errorf("VectorTransformTemplate %s has no transforms", templateName)
+	}
+	values, err := getTemplateValues(template, component["parameters"])
+	if err != nil {
+		return nil, err
+	}
+
+	var undefined []string
+	raw := templateParameterRegexp.ReplaceAllFunc(template.Spec.Transforms.Raw, func(m []byte) []byte {
+		param := string(templateParameterRegexp.FindSubmatch(m)[1])
+		value, ok := values[param]
+		if !ok {
+			undefined = append(undefined, param)
+			return m
+		}
+		// Parameters are used in JSON strings, so value is escaped
+		escaped, _ := json.Marshal(value)
+		return escaped[1 : len(escaped)-1]
+	})
+	if len(undefined) != 0 {
+		return nil, fmt.Errorf("VectorTransformTemplate %s uses undefined parameters: %s", templateName, strings.Join(undefined, ", "))
+	}
+	templateTransforms, err := decodeRaw(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	output := template.Spec.Output
+	if output == "" && len(templateTransforms) == 1 {
+		for t := range templateTransforms {
+			output = t
+		}
+	}
+	if _, ok := templateTransforms[output]; !ok {
+		return nil, fmt.Errorf("VectorTransformTemplate %s output %q doesn't match any transform", templateName, output)
+	}
+	rename := func(t string) string {
+		if t == output {
+			return name
+		}
+		return name + "-" + t
+	}
+
+	inputs, _ := component[inputsKey].([]interface{})
+	result := make(map[string]interface{}, len(templateTransforms))
+	for t, v := range templateTransforms {
+		transform, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("VectorTransformTemplate %s transform %s must be an object", templateName, t)
+		}
+		if transform["type"] == TemplateTransformType {
+			return nil, fmt.Errorf("VectorTransformTemplate %s transform %s can't reference template", templateName, t)
+		}
+		templateInputs, _ := transform[inputsKey].([]interface{})
+		if len(templateInputs) == 0 {
+			transform[inputsKey] = append([]interface{}{}, inputs...)
+			result[rename(t)] = transform
+			continue
+		}
+		renamed := make([]interface{}, 0, len(templateInputs))
+		for _, input := range templateInputs {
+			s, _ := input.(string)
+			// Inputs can be routes of route transform: <transform>.<route>
+			base, route := s, ""
+			if i := strings.Index(s, "."); i >= 0 {
+				base, route = s[:i], s[i:]
+			}
+			if _, ok := templateTransforms[base]; !ok {
+				return nil, fmt.Errorf("VectorTransformTemplate %s transform %s input %q doesn't match any template transform", templateName, t, s)
+			}
+			renamed = append(renamed, rename(base)+route)
+		}
+		transform[inputsKey] = renamed
+		result[rename(t)] = transform
+	}
+	return result, nil
+}
+
+// getTemplateValues returns values of all template parameters: set in pipeline or defaults
+func getTemplateValues(template *vectorv1alpha1.VectorTransformTemplate, parameters interface{}) (map[string]string, error) {
+	params, ok := parameters.(map[string]interface{})
+	if parameters != nil && !ok {
+		return nil, fmt.Errorf("parameters must be an object")
+	}
+	values := make(map[string]string, len(params))
+	for k, v := range params {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("parameter %s must be a string", k)
+		}
+		values[k] = s
+	}
+
+	defined := make(map[string]bool, len(template.Spec.Parameters))
+	for _, param := range template.Spec.Parameters {
+		defined[param.Name] = true
+		if _, ok := values[param.Name]; ok {
+			continue
+		}
+		if param.Default == nil {
+			return nil, fmt.Errorf("parameter %s of VectorTransformTemplate %s is required", param.Name, template.Name)
+		}
+		values[param.Name] = *param.Default
+	}
+
+	var unknown []string
+	for k := range values {
+		if !defined[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) != 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("VectorTransformTemplate %s has no parameters: %s", template.Name, strings.Join(unknown, ", "))
+	}
+	return values, nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/config"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newTestTemplate(name, transforms, output string, params ...vectorv1alpha1.TransformTemplateParameter) *vectorv1alpha1.VectorTransformTemplate {
+	return &vectorv1alpha1.VectorTransformTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: vectorv1alpha1.VectorTransformTemplateSpec{
+			Parameters: params,
+			Transforms: &runtime.RawExtension{Raw: []byte(transforms)},
+			Output:     output,
+		},
+	}
+}
+
+func TestBuilderTemplates(t *testing.T) {
+	type testCase struct {
+		name       string
+		transforms string
+		typed      map[string]vectorv1alpha1.TransformSpec
+		sinkInputs string
+		want       map[string]interface{}
+		wantErr    bool
+	}
+
+	defaultField := "message"
+	templates := config.Templates{
+		"parse-json": newTestTemplate("parse-json",
+			`{"parse":{"type":"remap","source":". = parse_json!(.$(field))"}}`, "",
+			vectorv1alpha1.TransformTemplateParameter{Name: "field", Default: &defaultField},
+		),
+		"drop-level": newTestTemplate("drop-level",
+			`{"route":{"type":"route","route":{"drop":".level == \"$(level)\""}},"out":{"type":"remap","inputs":["route._unmatched"],"source":"."}}`, "out",
+			vectorv1alpha1.TransformTemplateParameter{Name: "level"},
+		),
+		"undefined": newTestTemplate("undefined", `{"parse":{"type":"remap","source":".$(field)"}}`, ""),
+		"nested":    newTestTemplate("nested", `{"t":{"type":"template","name":"parse-json"}}`, ""),
+	}
+
+	testCases := []testCase{
+		{
+			name:       "Parameter default",
+			transforms: `{"json":{"type":"template","name":"parse-json","inputs":["source1"]}}`,
+			sinkInputs: "json",
+			want: map[string]interface{}{
+				"test-p1-json": map[string]interface{}{
+					"type":   "remap",
+					"inputs": []interface{}{"test-p1-source1"},
+					"source": ". = parse_json!(.message)",
+				},
+			},
+		},
+		{
+			name:       "Parameter value is escaped",
+			transforms: `{"json":{"type":"template","name":"parse-json","inputs":["source1"],"parameters":{"field":"\"log\""}}}`,
+			sinkInputs: "json",
+			want: map[string]interface{}{
+				"test-p1-json": map[string]interface{}{
+					"type":   "remap",
+					"inputs": []interface{}{"test-p1-source1"},
+					"source": ". = parse_json!(.\"log\")",
+				},
+			},
+		},
+		{
+			name:       "Several transforms with route input",
+			transforms: `{"filter":{"type":"template","name":"drop-level","inputs":["source1"],"parameters":{"level":"debug"}}}`,
+			sinkInputs: "filter",
+			want: map[string]interface{}{
+				"test-p1-filter-route": map[string]interface{}{
+					"type":   "route",
+					"inputs": []interface{}{"test-p1-source1"},
+					"route":  map[string]interface{}{"drop": ".level == \"debug\""},
+				},
+				"test-p1-filter": map[string]interface{}{
+					"type":   "remap",
+					"inputs": []interface{}{"test-p1-filter-route._unmatched"},
+					"source": ".",
+				},
+			},
+		},
+		{
+			name: "Typed template transform",
+			typed: map[string]vectorv1alpha1.TransformSpec{
+				"json": {Inputs: []string{"source1"}, Template: &vectorv1alpha1.TemplateTransform{
+					Name:       "parse-json",
+					Parameters: map[string]string{"field": "log"},
+				}},
+			},
+			sinkInputs: "json",
+			want: map[string]interface{}{
+				"test-p1-json": map[string]interface{}{
+					"type":   "remap",
+					"inputs": []interface{}{"test-p1-source1"},
+					"source": ". = parse_json!(.log)",
+				},
+			},
+		},
+		{
+			name:       "Template not found",
+			transforms: `{"json":{"type":"template","name":"missing","inputs":["source1"]}}`,
+			sinkInputs: "json",
+			wantErr:    true,
+		},
+		{
+			name:       "Required parameter is not set",
+			transforms: `{"filter":{"type":"template","name":"drop-level","inputs":["source1"]}}`,
+			sinkInputs: "filter",
+			wantErr:    true,
+		},
+		{
+			name:       "Unknown parameter",
+			transforms: `{"json":{"type":"template","name":"parse-json","inputs":["source1"],"parameters":{"name":"log"}}}`,
+			sinkInputs: "json",
+			wantErr:    true,
+		},
+		{
+			name:       "Undefined parameter in template",
+			transforms: `{"json":{"type":"template","name":"undefined","inputs":["source1"]}}`,
+			sinkInputs: "json",
+			wantErr:    true,
+		},
+		{
+			name:       "Nested template",
+			transforms: `{"json":{"type":"template","name":"nested","inputs":["source1"]}}`,
+			sinkInputs: "json",
+			wantErr:    true,
+		},
+		{
+			name:       "Expanded transform clashes with pipeline transform",
+			transforms: `{"filter":{"type":"template","name":"drop-level","inputs":["source1"],"parameters":{"level":"debug"}},"filter-route":{"type":"remap","inputs":["source1"],"source":"."}}`,
+			sinkInputs: "filter",
+			wantErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newTestPipeline("p1", "")
+			if tc.transforms != "" {
+				p.Spec.Transforms = &runtime.RawExtension{Raw: []byte(tc.transforms)}
+			}
+			p.Spec.TypedTransforms = tc.typed
+			p.Spec.Sinks = &runtime.RawExtension{
+				Raw: []byte(`{"sink1":{"type":"console","inputs":["` + tc.sinkInputs + `"],"encoding":{"codec":"json"}}}`),
+			}
+
+			data, err := config.NewBuilder(newTestAgentController(), p).WithTemplates(templates).GetByteConfig()
+			if tc.wantErr {
+				require.ErrorIs(t, err, config.PipelineTemplateError)
+				require.Equal(t, vectorv1alpha1.ReasonInvalidTemplate, config.GetConditionReason(err))
+				return
+			}
+			require.NoError(t, err)
+
+			cfg := make(map[string]interface{})
+			require.NoError(t, json.Unmarshal(data, &cfg))
+			require.Equal(t, tc.want, cfg["transforms"])
+		})
+	}
+}
+
+func TestBuilderTemplatesNotSet(t *testing.T) {
+	p := newTestPipeline("p1", "")
+	p.Spec.Transforms = &runtime.RawExtension{Raw: []byte(`{"json":{"type":"template","name":"parse-json","inputs":["source1"]}}`)}
+
+	_, err := config.NewBuilder(newTestAgentController(), p).GetByteConfig()
+	require.ErrorIs(t, err, config.PipelineTemplateError)
+}
+
+func TestReferencesTemplate(t *testing.T) {
+	p := newTestPipeline("p1", "")
+	p.Spec.Transforms = &runtime.RawExtension{Raw: []byte(`{"json":{"type":"template","name":"parse-json","inputs":["source1"]}}`)}
+	p.Spec.TypedTransforms = map[string]vectorv1alpha1.TransformSpec{
+		"filter": {Inputs: []string{"json"}, Template: &vectorv1alpha1.TemplateTransform{Name: "drop-level"}},
+	}
+
+	require.True(t, config.ReferencesTemplate(p, "parse-json"))
+	require.True(t, config.ReferencesTemplate(p, "drop-level"))
+	require.False(t, config.ReferencesTemplate(p, "missing"))
+	require.False(t, config.ReferencesTemplate(newTestPipeline("p2", ""), "parse-json"))
+}
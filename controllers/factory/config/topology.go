@@ -0,0 +1,244 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/kaasops/vector-operator/controllers/factory/pipeline"
+)
+
+var PipelineTopologyError = errors.New("invalid pipeline topology")
+
+// TopologyError is a problem in Vector config graph with originating pipeline
+type TopologyError struct {
+	// Pipeline is namespace/name of pipeline, empty for components added by operator
+	Pipeline  string
+	Component string
+	Message   string
+}
+
+func (e TopologyError) Error() string {
+	if e.Pipeline == "" {
+		return fmt.Sprintf("%s: %s", e.Component, e.Message)
+	}
+	return fmt.Sprintf("pipeline %s: %s: %s", e.Pipeline, e.Component, e.Message)
+}
+
+// TopologyErrors is a list of problems found by CheckTopology
+type TopologyErrors []TopologyError
+
+func (e TopologyErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e TopologyErrors) Unwrap() error {
+	return PipelineTopologyError
+}
+
+// Pipelines returns pipelines with topology errors
+func (e TopologyErrors) Pipelines() []string {
+	var pipelines []string
+	for _, err := range e {
+		if err.Pipeline != "" && !contains(pipelines, err.Pipeline) {
+			pipelines = append(pipelines, err.Pipeline)
+		}
+	}
+	return pipelines
+}
+
+// CheckTopology analyses config graph. It finds inputs, that don't match any source or transform,
+// sources without consumers and cycles between transforms
+func (c *VectorConfig) CheckTopology() error {
+	var errs TopologyErrors
+	consumed := make(map[string]bool)
+
+	checkInputs := func(name, pipeline string, inputs []string) {
+		for _, input := range inputs {
+			matched := c.matchInput(input)
+			if len(matched) == 0 {
+				errs = append(errs, TopologyError{
+					Pipeline:  pipeline,
+					Component: componentName(name, pipeline),
+					Message:   fmt.Sprintf("input %q doesn't match any source or transform", componentName(input, pipeline)),
+				})
+			}
+			for _, m := range matched {
+				consumed[m] = true
+			}
+		}
+	}
+	for _, t := range c.Transforms {
+		checkInputs(t.Name, t.Pipeline, t.Inputs)
+	}
+	for _, s := range c.Sinks {
+		checkInputs(s.Name, s.Pipeline, s.Inputs)
+	}
+
+	for _, s := range c.Sources {
+		if !consumed[s.Name] {
+			errs = append(errs, TopologyError{
+				Pipeline:  s.Pipeline,
+				Component: componentName(s.Name, s.Pipeline),
+				Message:   "source is not used as input by any transform or sink",
+			})
+		}
+	}
+
+	errs = append(errs, c.checkCycles()...)
+
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// matchInput returns names of sources and transforms matched by input.
+// Input can be component name, transform output (<transform>.<output>) or wildcard
+func (c *VectorConfig) matchInput(input string) []string {
+	var matched []string
+	if strings.Contains(input, "*") {
+		for _, s := range c.Sources {
+			if ok, _ := path.Match(input, s.Name); ok {
+				matched = append(matched, s.Name)
+			}
+		}
+		for _, t := range c.Transforms {
+			if ok, _ := path.Match(input, t.Name); ok {
+				matched = append(matched, t.Name)
+			}
+		}
+		return matched
+	}
+	for _, s := range c.Sources {
+		if s.Name == input {
+			return []string{s.Name}
+		}
+	}
+	if t := c.findTransformByInput(input); t != nil {
+		return []string{t.Name}
+	}
+	return nil
+}
+
+// findTransformByInput returns transform for input. Component names can contain dots, so all prefixes are checked
+func (c *VectorConfig) findTransformByInput(input string) *Transform {
+	for name := input; ; {
+		for _, t := range c.Transforms {
+			if t.Name == name {
+				return t
+			}
+		}
+		i := strings.LastIndex(name, ".")
+		if i <= 0 {
+			return nil
+		}
+		name = name[:i]
+	}
+}
+
+func (c *VectorConfig) checkCycles() (errs TopologyErrors) {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int, len(c.Transforms))
+
+	var visit func(t *Transform, stack []string)
+	visit = func(t *Transform, stack []string) {
+		state[t.Name] = inProgress
+		stack = append(stack, t.Name)
+		for _, input := range t.Inputs {
+			dep := c.findTransformByInput(input)
+			if dep == nil {
+				continue
+			}
+			switch state[dep.Name] {
+			case inProgress:
+				var cycle []string
+				for i := len(stack) - 1; i >= 0; i-- {
+					cycle = append([]string{componentName(stack[i], t.Pipeline)}, cycle...)
+					if stack[i] == dep.Name {
+						break
+					}
+				}
+				cycle = append(cycle, componentName(dep.Name, t.Pipeline))
+				errs = append(errs, TopologyError{
+					Pipeline:  t.Pipeline,
+					Component: componentName(t.Name, t.Pipeline),
+					Message:   "cycle in transforms: " + strings.Join(cycle, " -> "),
+				})
+			case unvisited:
+				visit(dep, stack)
+			}
+		}
+		state[t.Name] = done
+	}
+
+	for _, t := range c.Transforms {
+		if state[t.Name] == unvisited {
+			visit(t, nil)
+		}
+	}
+	return errs
+}
+
+// pipelineRef returns pipeline reference used in TopologyError: namespace/name or name for cluster pipelines
+func pipelineRef(namespace, name string) string {
+	if namespace != "" {
+		return namespace + "/" + name
+	}
+	return name
+}
+
+// componentName returns component name as it is defined in pipeline
+func componentName(name, pipeline string) string {
+	if pipeline == "" {
+		return name
+	}
+	return strings.TrimPrefix(name, strings.Replace(pipeline, "/", "-", 1)+"-")
+}
+
+// NewComponentResolver returns func, that maps prefixed component name in generated config back to
+// pipeline ref and component name in pipeline. The longest matching prefix wins. Components without
+// pipeline prefix (added by operator or merged) are returned as is with empty pipeline
+func NewComponentResolver(pipelines ...pipeline.Pipeline) func(name string) (string, string) {
+	prefixes := make(map[string]string, len(pipelines))
+	for _, p := range pipelines {
+		prefixes[addPrefix(p.GetNamespace(), p.GetName(), "")] = pipelineRef(p.GetNamespace(), p.GetName())
+	}
+	return func(name string) (string, string) {
+		var ref, longest string
+		for prefix, r := range prefixes {
+			if len(prefix) > len(longest) && len(name) > len(prefix) && strings.HasPrefix(name, prefix) {
+				ref, longest = r, prefix
+			}
+		}
+		if ref == "" {
+			return "", name
+		}
+		return ref, strings.TrimPrefix(name, longest)
+	}
+}
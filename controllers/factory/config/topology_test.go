@@ -0,0 +1,147 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/config"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestCheckTopology(t *testing.T) {
+	type testCase struct {
+		name          string
+		config        *config.VectorConfig
+		wantErrs      []string
+		wantPipelines []string
+	}
+
+	cases := []testCase{
+		{
+			name: "Valid topology",
+			config: &config.VectorConfig{
+				Sources: []*config.Source{{Name: "ns-p-src", Pipeline: "ns/p"}},
+				Transforms: []*config.Transform{
+					{Name: "ns-p-route", Pipeline: "ns/p", Inputs: []string{"ns-p-src"}},
+					{Name: "ns-p-remap", Pipeline: "ns/p", Inputs: []string{"ns-p-route.a"}},
+				},
+				Sinks: []*config.Sink{{Name: "ns-p-sink", Pipeline: "ns/p", Inputs: []string{"ns-p-remap", "ns-p-route._unmatched"}}},
+			},
+		},
+		{
+			name: "Wildcard input",
+			config: &config.VectorConfig{
+				Sources: []*config.Source{{Name: "ns-p-src1", Pipeline: "ns/p"}, {Name: "ns-p-src2", Pipeline: "ns/p"}},
+				Sinks:   []*config.Sink{{Name: "ns-p-sink", Pipeline: "ns/p", Inputs: []string{"ns-p-src*"}}},
+			},
+		},
+		{
+			name: "Dangling input",
+			config: &config.VectorConfig{
+				Sources: []*config.Source{{Name: "ns-p-src", Pipeline: "ns/p"}},
+				Sinks:   []*config.Sink{{Name: "ns-p-sink", Pipeline: "ns/p", Inputs: []string{"ns-p-src", "ns-p-srcc"}}},
+			},
+			wantErrs:      []string{`pipeline ns/p: sink: input "srcc" doesn't match any source or transform`},
+			wantPipelines: []string{"ns/p"},
+		},
+		{
+			name: "Orphaned source",
+			config: &config.VectorConfig{
+				Sources: []*config.Source{{Name: "ns-p-src", Pipeline: "ns/p"}, {Name: "cluster-src", Pipeline: "cluster"}},
+				Sinks:   []*config.Sink{{Name: "ns-p-sink", Pipeline: "ns/p", Inputs: []string{"ns-p-src"}}},
+			},
+			wantErrs:      []string{"pipeline cluster: src: source is not used as input by any transform or sink"},
+			wantPipelines: []string{"cluster"},
+		},
+		{
+			name: "Cycle",
+			config: &config.VectorConfig{
+				Sources: []*config.Source{{Name: "ns-p-src", Pipeline: "ns/p"}},
+				Transforms: []*config.Transform{
+					{Name: "ns-p-a", Pipeline: "ns/p", Inputs: []string{"ns-p-src", "ns-p-b"}},
+					{Name: "ns-p-b", Pipeline: "ns/p", Inputs: []string{"ns-p-a"}},
+				},
+				Sinks: []*config.Sink{{Name: "ns-p-sink", Pipeline: "ns/p", Inputs: []string{"ns-p-b"}}},
+			},
+			wantErrs:      []string{"pipeline ns/p: b: cycle in transforms: a -> b -> a"},
+			wantPipelines: []string{"ns/p"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.CheckTopology()
+			if len(tc.wantErrs) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, config.PipelineTopologyError)
+			var topologyErrs config.TopologyErrors
+			require.True(t, errors.As(err, &topologyErrs))
+			var msgs []string
+			for _, e := range topologyErrs {
+				msgs = append(msgs, e.Error())
+			}
+			require.Equal(t, tc.wantErrs, msgs)
+			require.Equal(t, tc.wantPipelines, topologyErrs.Pipelines())
+		})
+	}
+}
+
+func TestBuilderTopologyError(t *testing.T) {
+	p := newTestPipeline("p1", "")
+	p.Spec.Sinks = &runtime.RawExtension{
+		Raw: []byte(`{"sink1":{"type":"console","inputs":["sourc1"],"encoding":{"codec":"json"}}}`),
+	}
+
+	_, err := config.NewBuilder(newTestAgentController(), p).GetByteConfig()
+	require.ErrorIs(t, err, config.PipelineTopologyError)
+	require.Contains(t, err.Error(), `pipeline test/p1: sink1: input "sourc1" doesn't match any source or transform`)
+	require.Contains(t, err.Error(), `pipeline test/p1: source1: source is not used as input by any transform or sink`)
+}
+
+func TestNewComponentResolver(t *testing.T) {
+	resolve := config.NewComponentResolver(
+		&vectorv1alpha1.VectorPipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "p"}},
+		&vectorv1alpha1.VectorPipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "p-logs"}},
+		&vectorv1alpha1.ClusterVectorPipeline{ObjectMeta: metav1.ObjectMeta{Name: "cp"}},
+	)
+
+	cases := []struct {
+		name          string
+		wantPipeline  string
+		wantComponent string
+	}{
+		{"ns-p-sink", "ns/p", "sink"},
+		{"ns-p-logs-sink", "ns/p-logs", "sink"},
+		{"cp-src", "cp", "src"},
+		{"mergedKubernetesSource", "", "mergedKubernetesSource"},
+		{"ns-p-", "", "ns-p-"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pipeline, component := resolve(tc.name)
+			require.Equal(t, tc.wantPipeline, pipeline)
+			require.Equal(t, tc.wantComponent, component)
+		})
+	}
+}
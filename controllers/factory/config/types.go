@@ -16,6 +16,8 @@ limitations under the License.
 
 package config
 
+import "github.com/kaasops/vector-operator/controllers/factory/valueref"
+
 type VectorConfig struct {
 	DataDir    string       `mapstructure:"data_dir"`
 	Api        *ApiSpec     `mapstructure:"api"`
@@ -26,27 +28,33 @@ type VectorConfig struct {
 
 type Source struct {
 	Name                        string
+	Pipeline                    string                 `mapstructure:"-"`
 	Type                        string                 `mapper:"type"`
 	ExtraNamespaceLabelSelector string                 `mapstructure:"extra_namespace_label_selector" mapper:"extra_namespace_label_selector,omitempty"`
 	ExtraLabelSelector          string                 `mapstructure:"extra_label_selector" mapper:"extra_label_selector,omitempty"`
 	ExtraFieldSelector          string                 `mapstructure:"extra_field_selector" mapper:"extra_field_selector,omitempty"`
 	Options                     map[string]interface{} `mapstructure:",remain"`
+	ValueRefs                   []valueref.Ref         `mapstructure:"-"`
 }
 
 type Transform struct {
-	Name    string
-	Type    string                 `mapper:"type"`
-	Inputs  []string               `mapper:"inputs"`
-	Route   map[string]string      `mapper:"route,omitempty"`
-	Options map[string]interface{} `mapstructure:",remain"`
+	Name      string
+	Pipeline  string                 `mapstructure:"-"`
+	Type      string                 `mapper:"type"`
+	Inputs    []string               `mapper:"inputs"`
+	Route     map[string]string      `mapper:"route,omitempty"`
+	Options   map[string]interface{} `mapstructure:",remain"`
+	ValueRefs []valueref.Ref         `mapstructure:"-"`
 }
 
 type Sink struct {
 	Name        string
+	Pipeline    string                 `mapstructure:"-"`
 	Type        string                 `mapper:"type"`
 	Inputs      []string               `mapper:"inputs"`
 	Options     map[string]interface{} `mapstructure:",remain"`
 	OptionsHash string
+	ValueRefs   []valueref.Ref `mapstructure:"-"`
 }
 
 type ConfigComponent interface {
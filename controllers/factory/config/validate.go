@@ -0,0 +1,125 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/kaasops/vector-operator/controllers/factory/pipeline"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidatePipeline runs pipeline checks, that don't require Vector instance: JSON shape of components,
+// namespace restrictions for sources and pipeline topology. It is used by admission webhook
+func ValidatePipeline(p pipeline.Pipeline) field.ErrorList {
+	spec := p.GetSpec()
+	specPath := field.NewPath("spec")
+
+	var errs field.ErrorList
+	errs = append(errs, validateComponentsShape(spec.Sources, specPath.Child("sources"), false)...)
+	errs = append(errs, validateComponentsShape(spec.Transforms, specPath.Child("transforms"), true)...)
+	errs = append(errs, validateComponentsShape(spec.Sinks, specPath.Child("sinks"), true)...)
+	if len(errs) != 0 {
+		return errs
+	}
+
+	sources, err := getSources(p, nil)
+	if err != nil {
+		return append(errs, field.Invalid(specPath.Child("sources"), rawValue(spec.Sources), err.Error()))
+	}
+	for _, source := range sources {
+		if err := checkSourceScope(p, source); err != nil {
+			errs = append(errs, field.Forbidden(specPath.Child("sources", componentName(source.Name, source.Pipeline)), err.Error()))
+		}
+	}
+	transforms, err := getTransforms(p, nil)
+	if err != nil {
+		return append(errs, field.Invalid(specPath.Child("transforms"), rawValue(spec.Transforms), err.Error()))
+	}
+	sinks, err := getSinks(p)
+	if err != nil {
+		return append(errs, field.Invalid(specPath.Child("sinks"), rawValue(spec.Sinks), err.Error()))
+	}
+
+	cfg := &VectorConfig{Sources: sources, Transforms: transforms, Sinks: sinks}
+	if err := cfg.CheckTopology(); err != nil {
+		var topologyErrs TopologyErrors
+		if !errors.As(err, &topologyErrs) {
+			return append(errs, field.Invalid(specPath, nil, err.Error()))
+		}
+		for _, e := range topologyErrs {
+			errs = append(errs, field.Invalid(specPath, e.Component, e.Message))
+		}
+	}
+	return errs
+}
+
+// rawValue returns raw components for error message, typed components are reported by name in error
+func rawValue(raw *runtime.RawExtension) interface{} {
+	if raw == nil {
+		return nil
+	}
+	return string(raw.Raw)
+}
+
+// validateComponentsShape checks components is an object of components, every component has type
+// and transforms and sinks have inputs
+func validateComponentsShape(raw *runtime.RawExtension, path *field.Path, withInputs bool) field.ErrorList {
+	if raw == nil || len(raw.Raw) == 0 {
+		return nil
+	}
+	var components map[string]json.RawMessage
+	if err := json.Unmarshal(raw.Raw, &components); err != nil {
+		return field.ErrorList{field.Invalid(path, string(raw.Raw), "must be an object of components")}
+	}
+
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs field.ErrorList
+	for _, name := range names {
+		componentPath := path.Child(name)
+		var component map[string]interface{}
+		if err := json.Unmarshal(components[name], &component); err != nil || component == nil {
+			errs = append(errs, field.Invalid(componentPath, string(components[name]), "must be an object"))
+			continue
+		}
+		if t, ok := component["type"].(string); !ok || t == "" {
+			errs = append(errs, field.Required(componentPath.Child("type"), "must be a non-empty string"))
+		}
+		if !withInputs {
+			continue
+		}
+		inputs, ok := component["inputs"].([]interface{})
+		if !ok || len(inputs) == 0 {
+			errs = append(errs, field.Required(componentPath.Child("inputs"), "must be a non-empty list of component names"))
+			continue
+		}
+		for i, input := range inputs {
+			if s, ok := input.(string); !ok || s == "" {
+				errs = append(errs, field.Invalid(componentPath.Child("inputs").Index(i), input, "must be a non-empty string"))
+			}
+		}
+	}
+	return errs
+}
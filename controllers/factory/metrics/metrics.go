@@ -0,0 +1,169 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kaasops/vector-operator/controllers/factory/utils/compression"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "vector_operator"
+
+// Config check results
+const (
+	ResultPassed                = "passed"
+	ResultFailed                = "failed"
+	ResultTimeout               = "timeout"
+	ResultInfrastructureFailure = "infrastructure_failure"
+	ResultError                 = "error"
+)
+
+// Label values of config metrics
+const (
+	RoleAgent      = "agent"
+	RoleAggregator = "aggregator"
+
+	EncodingRaw  = "raw"
+	EncodingGzip = "gzip"
+
+	KindSource    = "source"
+	KindTransform = "transform"
+	KindSink      = "sink"
+
+	StageBeforeMerge = "before_merge"
+	StageAfterMerge  = "after_merge"
+)
+
+var (
+	// ConfigCheckDuration is duration of config checks in configcheck pod by initiator and result
+	ConfigCheckDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "configcheck_duration_seconds",
+			Help:      "Duration of Vector config check in configcheck pod",
+			Buckets:   []float64{1, 2.5, 5, 10, 20, 30, 60, 120, 300},
+		},
+		[]string{"initiator", "result"},
+	)
+	// ConfigCheckOrphanedResourcesRemoved counts config check pods and Secrets removed by janitor
+	ConfigCheckOrphanedResourcesRemoved = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "configcheck_orphaned_resources_removed_total",
+			Help:      "Number of orphaned config check resources removed by janitor",
+		},
+		[]string{"kind"},
+	)
+	// ConfigCheckQueueDepth is number of config checks waiting for free slot by initiator
+	ConfigCheckQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "configcheck_queue_depth",
+			Help:      "Number of config checks waiting for free configcheck pod slot",
+		},
+		[]string{"initiator"},
+	)
+	// ConfigCheckInFlight is number of running configcheck pods
+	ConfigCheckInFlight = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "configcheck_in_flight",
+			Help:      "Number of running config checks",
+		},
+	)
+	// ConfigSize is size of generated Vector config
+	ConfigSize = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "config_size_bytes",
+			Help:      "Size of generated Vector config, raw and gzipped",
+		},
+		[]string{"namespace", "vector", "role", "encoding"},
+	)
+	// ConfigComponents is number of components in generated Vector config before and after merge
+	ConfigComponents = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "config_components",
+			Help:      "Number of sources, transforms and sinks in generated Vector config before and after merge",
+		},
+		[]string{"namespace", "vector", "role", "kind", "stage"},
+	)
+	// LastSuccessfulApply is timestamp of the last successful apply of Vector config
+	LastSuccessfulApply = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_successful_apply_timestamp_seconds",
+			Help:      "Unix timestamp of the last successful apply of Vector config",
+		},
+		[]string{"namespace", "vector"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		ConfigCheckDuration,
+		ConfigCheckOrphanedResourcesRemoved,
+		ConfigCheckQueueDepth,
+		ConfigCheckInFlight,
+		ConfigSize,
+		ConfigComponents,
+		LastSuccessfulApply,
+	)
+}
+
+// ObserveConfigCheck records config check duration
+func ObserveConfigCheck(initiator, result string, duration time.Duration) {
+	ConfigCheckDuration.WithLabelValues(initiator, result).Observe(duration.Seconds())
+}
+
+// SetConfigSize records raw and gzipped size of generated Vector config
+func SetConfigSize(vectorNamespace, vectorName, role string, config []byte) {
+	ConfigSize.WithLabelValues(vectorNamespace, vectorName, role, EncodingRaw).Set(float64(len(config)))
+	ConfigSize.WithLabelValues(vectorNamespace, vectorName, role, EncodingGzip).Set(float64(len(compression.Compress(config, logr.Discard()))))
+}
+
+// SetConfigComponents records number of components in generated Vector config on merge stage
+func SetConfigComponents(vectorNamespace, vectorName, role, stage string, sources, transforms, sinks int) {
+	ConfigComponents.WithLabelValues(vectorNamespace, vectorName, role, KindSource, stage).Set(float64(sources))
+	ConfigComponents.WithLabelValues(vectorNamespace, vectorName, role, KindTransform, stage).Set(float64(transforms))
+	ConfigComponents.WithLabelValues(vectorNamespace, vectorName, role, KindSink, stage).Set(float64(sinks))
+}
+
+// SetLastSuccessfulApply records time of successful apply of Vector config
+func SetLastSuccessfulApply(vectorNamespace, vectorName string, t time.Time) {
+	LastSuccessfulApply.WithLabelValues(vectorNamespace, vectorName).Set(float64(t.Unix()))
+}
+
+// DeleteVector removes metrics of deleted Vector
+func DeleteVector(vectorNamespace, vectorName string) {
+	for _, role := range []string{RoleAgent, RoleAggregator} {
+		for _, encoding := range []string{EncodingRaw, EncodingGzip} {
+			ConfigSize.DeleteLabelValues(vectorNamespace, vectorName, role, encoding)
+		}
+		for _, kind := range []string{KindSource, KindTransform, KindSink} {
+			for _, stage := range []string{StageBeforeMerge, StageAfterMerge} {
+				ConfigComponents.DeleteLabelValues(vectorNamespace, vectorName, role, kind, stage)
+			}
+		}
+	}
+	LastSuccessfulApply.DeleteLabelValues(vectorNamespace, vectorName)
+}
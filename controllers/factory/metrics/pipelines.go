@@ -0,0 +1,109 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const collectTimeout = 5 * time.Second
+
+// PipelineCollector collects numbers of valid and invalid pipelines per namespace from operator cache.
+// ClusterVectorPipelines are reported with empty namespace. Pipelines without config check result are skipped
+type PipelineCollector struct {
+	client client.Reader
+	desc   *prometheus.Desc
+}
+
+func NewPipelineCollector(c client.Reader) *PipelineCollector {
+	return &PipelineCollector{
+		client: c,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "pipelines"),
+			"Number of VectorPipelines and ClusterVectorPipelines by config check result",
+			[]string{"namespace", "valid"},
+			nil,
+		),
+	}
+}
+
+// RegisterPipelineCollector registers PipelineCollector in controller-runtime metrics registry
+func RegisterPipelineCollector(c client.Reader) error {
+	return metrics.Registry.Register(NewPipelineCollector(c))
+}
+
+func (pc *PipelineCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pc.desc
+}
+
+func (pc *PipelineCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	counts, err := pc.count(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(pc.desc, err)
+		return
+	}
+
+	namespaces := make([]string, 0, len(counts))
+	for ns := range counts {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	for _, ns := range namespaces {
+		ch <- prometheus.MustNewConstMetric(pc.desc, prometheus.GaugeValue, float64(counts[ns][true]), ns, "true")
+		ch <- prometheus.MustNewConstMetric(pc.desc, prometheus.GaugeValue, float64(counts[ns][false]), ns, "false")
+	}
+}
+
+func (pc *PipelineCollector) count(ctx context.Context) (map[string]map[bool]int, error) {
+	counts := make(map[string]map[bool]int)
+	add := func(namespace string, result *bool) {
+		if result == nil {
+			return
+		}
+		if counts[namespace] == nil {
+			counts[namespace] = make(map[bool]int)
+		}
+		counts[namespace][*result]++
+	}
+
+	vps := vectorv1alpha1.VectorPipelineList{}
+	if err := pc.client.List(ctx, &vps); err != nil {
+		return nil, err
+	}
+	for _, vp := range vps.Items {
+		add(vp.Namespace, vp.Status.ConfigCheckResult)
+	}
+
+	cvps := vectorv1alpha1.ClusterVectorPipelineList{}
+	if err := pc.client.List(ctx, &cvps); err != nil {
+		return nil, err
+	}
+	for _, cvp := range cvps.Items {
+		add("", cvp.Status.ConfigCheckResult)
+	}
+	return counts, nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPipelineCollector(t *testing.T) {
+	valid, invalid := true, false
+	newPipeline := func(namespace, name string, result *bool) *vectorv1alpha1.VectorPipeline {
+		return &vectorv1alpha1.VectorPipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Status:     vectorv1alpha1.VectorPipelineStatus{ConfigCheckResult: result},
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, vectorv1alpha1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newPipeline("ns1", "p1", &valid),
+		newPipeline("ns1", "p2", &invalid),
+		newPipeline("ns1", "p3", &invalid),
+		newPipeline("ns2", "p1", &valid),
+		newPipeline("ns3", "p1", nil),
+		&vectorv1alpha1.ClusterVectorPipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "cp1"},
+			Status:     vectorv1alpha1.VectorPipelineStatus{ConfigCheckResult: &valid},
+		},
+	).Build()
+
+	expected := `
+# HELP vector_operator_pipelines Number of VectorPipelines and ClusterVectorPipelines by config check result
+# TYPE vector_operator_pipelines gauge
+vector_operator_pipelines{namespace="",valid="false"} 0
+vector_operator_pipelines{namespace="",valid="true"} 1
+vector_operator_pipelines{namespace="ns1",valid="false"} 2
+vector_operator_pipelines{namespace="ns1",valid="true"} 1
+vector_operator_pipelines{namespace="ns2",valid="false"} 0
+vector_operator_pipelines{namespace="ns2",valid="true"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(metrics.NewPipelineCollector(c), strings.NewReader(expected)))
+}
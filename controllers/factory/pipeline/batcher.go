@@ -0,0 +1,124 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Batcher collects pipeline changes per Vector and checks them together, when no changes were added during
+// Window or batch is collected longer than MaxWait. So mass pipeline change runs one config check per Vector
+type Batcher struct {
+	Window  time.Duration
+	MaxWait time.Duration
+	// Check validates pipelines changed for Vector. Pipelines are empty, if only deleted pipelines were collected
+	Check func(ctx context.Context, v *vectorv1alpha1.Vector, pipelines []Pipeline)
+	// Done is called after Check, when Vector has no pending checks
+	Done func(v *vectorv1alpha1.Vector)
+
+	mu      sync.Mutex
+	batches map[types.NamespacedName]*batch
+	running map[types.NamespacedName]int
+}
+
+type batch struct {
+	ctx       context.Context
+	vector    *vectorv1alpha1.Vector
+	pipelines []Pipeline
+	started   time.Time
+	timer     *time.Timer
+}
+
+func NewBatcher(window, maxWait time.Duration) *Batcher {
+	return &Batcher{
+		Window:  window,
+		MaxWait: maxWait,
+		batches: make(map[types.NamespacedName]*batch),
+		running: make(map[types.NamespacedName]int),
+	}
+}
+
+// Add adds pipeline change to Vector batch. Nil pipeline is added for deleted pipelines, it only delays
+// Vector reconcile until batch is checked. The latest version of pipeline replaces the previous one
+func (b *Batcher) Add(ctx context.Context, v *vectorv1alpha1.Vector, p Pipeline) {
+	key := types.NamespacedName{Namespace: v.Namespace, Name: v.Name}
+	now := time.Now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bt, ok := b.batches[key]
+	if !ok {
+		bt = &batch{ctx: ctx, started: now}
+		b.batches[key] = bt
+		bt.timer = time.AfterFunc(b.Window, func() { b.flush(key, bt) })
+	} else {
+		delay := b.Window
+		if left := b.MaxWait - now.Sub(bt.started); left < delay {
+			delay = left
+		}
+		bt.timer.Reset(delay)
+	}
+	bt.vector = v
+	if p != nil {
+		bt.pipelines = append(Exclude(bt.pipelines, []Pipeline{p}), p)
+	}
+}
+
+// Pending returns true, if Vector has collected or running pipeline checks. Nil Batcher has no pending checks
+func (b *Batcher) Pending(key types.NamespacedName) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.batches[key]
+	return ok || b.running[key] != 0
+}
+
+func (b *Batcher) flush(key types.NamespacedName, bt *batch) {
+	b.mu.Lock()
+	if b.batches[key] != bt {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.batches, key)
+	b.running[key]++
+	b.mu.Unlock()
+
+	if b.Check != nil {
+		b.Check(bt.ctx, bt.vector, bt.pipelines)
+	}
+
+	b.mu.Lock()
+	b.running[key]--
+	if b.running[key] == 0 {
+		delete(b.running, key)
+	}
+	_, pending := b.batches[key]
+	pending = pending || b.running[key] != 0
+	b.mu.Unlock()
+
+	if !pending && b.Done != nil {
+		b.Done(bt.vector)
+	}
+}
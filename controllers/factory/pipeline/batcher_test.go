@@ -0,0 +1,98 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/pipeline"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestBatcher(t *testing.T) {
+	ctx := context.Background()
+	v1 := &vectorv1alpha1.Vector{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "v1"}}
+	v2 := &vectorv1alpha1.Vector{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "v2"}}
+	p1 := &vectorv1alpha1.VectorPipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "p1", Generation: 1}}
+	p1Updated := &vectorv1alpha1.VectorPipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "p1", Generation: 2}}
+	p2 := &vectorv1alpha1.VectorPipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "p2"}}
+
+	var mu sync.Mutex
+	checked := map[string][]pipeline.Pipeline{}
+	done := make(chan string, 2)
+
+	b := pipeline.NewBatcher(50*time.Millisecond, time.Second)
+	b.Check = func(_ context.Context, v *vectorv1alpha1.Vector, pipelines []pipeline.Pipeline) {
+		mu.Lock()
+		defer mu.Unlock()
+		checked[v.Name] = pipelines
+	}
+	b.Done = func(v *vectorv1alpha1.Vector) {
+		done <- v.Name
+	}
+
+	b.Add(ctx, v1, p1)
+	b.Add(ctx, v1, p2)
+	b.Add(ctx, v1, p1Updated)
+	b.Add(ctx, v2, nil)
+	require.True(t, b.Pending(types.NamespacedName{Namespace: "ns", Name: "v1"}))
+	require.False(t, b.Pending(types.NamespacedName{Namespace: "ns", Name: "v3"}))
+
+	require.ElementsMatch(t, []string{"v1", "v2"}, []string{<-done, <-done})
+	require.False(t, b.Pending(types.NamespacedName{Namespace: "ns", Name: "v1"}))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []pipeline.Pipeline{p2, p1Updated}, checked["v1"])
+	require.Contains(t, checked, "v2")
+	require.Empty(t, checked["v2"])
+}
+
+func TestBatcherMaxWait(t *testing.T) {
+	ctx := context.Background()
+	v := &vectorv1alpha1.Vector{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "v"}}
+	start := time.Now()
+	checks := make(chan time.Duration, 10)
+
+	b := pipeline.NewBatcher(100*time.Millisecond, 250*time.Millisecond)
+	b.Check = func(_ context.Context, _ *vectorv1alpha1.Vector, _ []pipeline.Pipeline) {
+		checks <- time.Since(start)
+	}
+
+	// Changes are added more often than window, so batch is flushed by max wait
+	for i := 0; i < 10; i++ {
+		b.Add(ctx, v, &vectorv1alpha1.VectorPipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: string(rune('a' + i))}})
+		time.Sleep(60 * time.Millisecond)
+	}
+	select {
+	case flushed := <-checks:
+		require.Less(t, flushed, 500*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("batch is not flushed")
+	}
+}
+
+func TestBatcherNil(t *testing.T) {
+	var b *pipeline.Batcher
+	require.False(t, b.Pending(types.NamespacedName{Namespace: "ns", Name: "v"}))
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"context"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+)
+
+// Ref returns pipeline reference, as it is used in config errors: namespace/name or name for ClusterVectorPipeline
+func Ref(p Pipeline) string {
+	if p.GetNamespace() != "" {
+		return p.GetNamespace() + "/" + p.GetName()
+	}
+	return p.GetName()
+}
+
+// Attribute returns pipelines, that config errors belong to. Errors of components without pipeline are ignored
+func Attribute(pipelines []Pipeline, configErrors []vectorv1alpha1.ConfigError) []Pipeline {
+	refs := make(map[string]struct{}, len(configErrors))
+	for _, e := range configErrors {
+		if e.Pipeline != "" {
+			refs[e.Pipeline] = struct{}{}
+		}
+	}
+	var attributed []Pipeline
+	for _, p := range pipelines {
+		if _, ok := refs[Ref(p)]; ok {
+			attributed = append(attributed, p)
+		}
+	}
+	return attributed
+}
+
+// ConfigErrorsFor returns config errors of pipeline
+func ConfigErrorsFor(p Pipeline, configErrors []vectorv1alpha1.ConfigError) []vectorv1alpha1.ConfigError {
+	var errs []vectorv1alpha1.ConfigError
+	for _, e := range configErrors {
+		if e.Pipeline == Ref(p) {
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
+
+// Exclude returns pipelines without excluded ones
+func Exclude(pipelines, excluded []Pipeline) []Pipeline {
+	refs := make(map[string]struct{}, len(excluded))
+	for _, p := range excluded {
+		refs[Ref(p)] = struct{}{}
+	}
+	var rest []Pipeline
+	for _, p := range pipelines {
+		if _, ok := refs[Ref(p)]; !ok {
+			rest = append(rest, p)
+		}
+	}
+	return rest
+}
+
+// NewExclusion returns record of pipeline, that is excluded from Vector configs with reason and message
+func NewExclusion(p Pipeline, reason, message string) (vectorv1alpha1.ExcludedPipeline, error) {
+	hash, err := GetSpecHash(p)
+	if err != nil {
+		return vectorv1alpha1.ExcludedPipeline{}, err
+	}
+	return vectorv1alpha1.ExcludedPipeline{
+		Pipeline:     Ref(p),
+		PipelineHash: *hash,
+		Reason:       reason,
+		Message:      message,
+	}, nil
+}
+
+// ApplyExclusions returns pipelines without ones excluded by exclusions and exclusions, that are still in effect.
+// Exclusion is dropped, when pipeline is not selected anymore or its spec is changed since exclusion
+func ApplyExclusions(pipelines []Pipeline, exclusions []vectorv1alpha1.ExcludedPipeline) ([]Pipeline, []vectorv1alpha1.ExcludedPipeline, error) {
+	hashes := make(map[string]uint32, len(exclusions))
+	for _, e := range exclusions {
+		hashes[e.Pipeline] = e.PipelineHash
+	}
+	var rest, excluded []Pipeline
+	for _, p := range pipelines {
+		excludedHash, ok := hashes[Ref(p)]
+		if !ok {
+			rest = append(rest, p)
+			continue
+		}
+		hash, err := GetSpecHash(p)
+		if err != nil {
+			return nil, nil, err
+		}
+		if *hash != excludedHash {
+			rest = append(rest, p)
+			continue
+		}
+		excluded = append(excluded, p)
+	}
+	var kept []vectorv1alpha1.ExcludedPipeline
+	for _, e := range exclusions {
+		for _, p := range excluded {
+			if e.Pipeline == Ref(p) {
+				kept = append(kept, e)
+				break
+			}
+		}
+	}
+	return rest, kept, nil
+}
+
+// Bisect finds pipeline, that fails config check, in pipelines, that fail it together. Halves are checked with
+// failed func until single pipeline is left. Nil is returned, if both halves pass, so failure is caused by
+// combination of pipelines
+func Bisect(ctx context.Context, pipelines []Pipeline, failed func(context.Context, []Pipeline) (bool, error)) (Pipeline, error) {
+	if len(pipelines) == 0 {
+		return nil, nil
+	}
+	if len(pipelines) == 1 {
+		return pipelines[0], nil
+	}
+	half := len(pipelines) / 2
+	for _, part := range [][]Pipeline{pipelines[:half], pipelines[half:]} {
+		f, err := failed(ctx, part)
+		if err != nil {
+			return nil, err
+		}
+		if f {
+			return Bisect(ctx, part, failed)
+		}
+	}
+	return nil, nil
+}
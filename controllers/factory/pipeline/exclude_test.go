@@ -0,0 +1,145 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/pipeline"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testPipelines() []pipeline.Pipeline {
+	return []pipeline.Pipeline{
+		&vectorv1alpha1.VectorPipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "p1"}},
+		&vectorv1alpha1.VectorPipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "p2"}},
+		&vectorv1alpha1.VectorPipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "p3"}},
+		&vectorv1alpha1.ClusterVectorPipeline{ObjectMeta: metav1.ObjectMeta{Name: "cp"}},
+		&vectorv1alpha1.VectorPipeline{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "p4"}},
+	}
+}
+
+func refs(pipelines []pipeline.Pipeline) []string {
+	var r []string
+	for _, p := range pipelines {
+		r = append(r, pipeline.Ref(p))
+	}
+	return r
+}
+
+func TestAttributeAndExclude(t *testing.T) {
+	pipelines := testPipelines()
+	configErrors := []vectorv1alpha1.ConfigError{
+		{Pipeline: "ns/p2", Component: "sink", Kind: "Component", Message: "invalid sink"},
+		{Component: "mergedKubernetesSource", Kind: "Component", Message: "invalid source"},
+		{Pipeline: "cp", Component: "src", Kind: "Component", Message: "invalid source"},
+		{Pipeline: "ns/p2", Component: "remap", Kind: "Component", Message: "invalid transform"},
+		{Pipeline: "other/p", Component: "sink", Kind: "Component", Message: "unknown pipeline"},
+	}
+
+	failed := pipeline.Attribute(pipelines, configErrors)
+	require.Equal(t, []string{"ns/p2", "cp"}, refs(failed))
+	require.Equal(t, []string{"ns/p1", "ns/p3", "ns/p4"}, refs(pipeline.Exclude(pipelines, failed)))
+	require.Equal(t, []vectorv1alpha1.ConfigError{configErrors[0], configErrors[3]}, pipeline.ConfigErrorsFor(failed[0], configErrors))
+	require.Empty(t, pipeline.Attribute(pipelines, nil))
+}
+
+func TestBisect(t *testing.T) {
+	type testCase struct {
+		name    string
+		failing func(refs []string) bool
+		want    string
+		wantErr bool
+	}
+
+	contains := func(refs []string, ref string) bool {
+		for _, r := range refs {
+			if r == ref {
+				return true
+			}
+		}
+		return false
+	}
+
+	cases := []testCase{
+		{
+			name:    "Single failed pipeline",
+			failing: func(refs []string) bool { return contains(refs, "cp") },
+			want:    "cp",
+		},
+		{
+			name:    "First failed pipeline",
+			failing: func(refs []string) bool { return contains(refs, "ns/p1") || contains(refs, "ns/p4") },
+			want:    "ns/p1",
+		},
+		{
+			name:    "Combination of pipelines",
+			failing: func(refs []string) bool { return contains(refs, "ns/p1") && contains(refs, "ns/p4") },
+		},
+		{
+			name:    "Check error",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			failed, err := pipeline.Bisect(context.Background(), testPipelines(), func(_ context.Context, pipelines []pipeline.Pipeline) (bool, error) {
+				if tc.failing == nil {
+					return false, errors.New("configcheck pod failed to start")
+				}
+				return tc.failing(refs(pipelines)), nil
+			})
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tc.want == "" {
+				require.Nil(t, failed)
+				return
+			}
+			require.Equal(t, tc.want, pipeline.Ref(failed))
+		})
+	}
+}
+
+func TestApplyExclusions(t *testing.T) {
+	pipelines := testPipelines()
+	excluded, err := pipeline.NewExclusion(pipelines[1], vectorv1alpha1.ReasonVectorConfigCheckFailed, "invalid sink")
+	require.NoError(t, err)
+	require.Equal(t, "ns/p2", excluded.Pipeline)
+	changed, err := pipeline.NewExclusion(pipelines[3], vectorv1alpha1.ReasonVectorConfigCheckFailed, "invalid source")
+	require.NoError(t, err)
+	changed.PipelineHash++
+	removed := vectorv1alpha1.ExcludedPipeline{Pipeline: "ns/removed", Reason: vectorv1alpha1.ReasonVectorConfigCheckFailed}
+
+	rest, kept, err := pipeline.ApplyExclusions(pipelines, []vectorv1alpha1.ExcludedPipeline{excluded, changed, removed})
+	require.NoError(t, err)
+	// Changed pipeline is tried again, exclusion of pipeline, that is not selected, is dropped
+	require.Equal(t, []string{"ns/p1", "ns/p3", "cp", "ns/p4"}, refs(rest))
+	require.Equal(t, []vectorv1alpha1.ExcludedPipeline{excluded}, kept)
+
+	rest, kept, err = pipeline.ApplyExclusions(pipelines, nil)
+	require.NoError(t, err)
+	require.Equal(t, refs(pipelines), refs(rest))
+	require.Empty(t, kept)
+}
@@ -18,27 +18,41 @@ package pipeline
 
 import (
 	"context"
+	"encoding/json"
 
 	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type Pipeline interface {
+	runtime.Object
 	GetSpec() vectorv1alpha1.VectorPipelineSpec
 	GetName() string
 	GetNamespace() string
+	GetLabels() map[string]string
 	Type() string
 	SetConfigCheck(bool)
 	SetReason(*string)
+	SetConfigErrors([]vectorv1alpha1.ConfigError)
+	SetCondition(conditionType string, status metav1.ConditionStatus, reason, message string)
+	SetRenderedConfigs([]vectorv1alpha1.RenderedConfig)
 	GetLastAppliedPipeline() *uint32
 	SetLastAppliedPipeline(*uint32)
+	GetAppliedSpec() *runtime.RawExtension
+	SetAppliedSpec(*runtime.RawExtension)
 	GetConfigCheckResult() *bool
 	IsValid() bool
 	IsDeleted() bool
 	UpdateStatus(context.Context, client.Client) error
 }
 
-func GetValidPipelines(ctx context.Context, client client.Client) ([]Pipeline, error) {
+// GetValidPipelines returns valid pipelines selected by Vector. Dry run doesn't change applied configs, so pipelines
+// in dry run mode are returned with the last checked spec and skipped, if they were never checked
+func GetValidPipelines(ctx context.Context, client client.Client, v *vectorv1alpha1.Vector) ([]Pipeline, error) {
 	var validPipelines []Pipeline
 	vps, err := GetVectorPipelines(ctx, client)
 	if err != nil {
@@ -48,34 +62,178 @@ func GetValidPipelines(ctx context.Context, client client.Client) ([]Pipeline, e
 	if err != nil {
 		return nil, err
 	}
+	namespaceLabels, err := getNamespaceLabels(ctx, client, v)
+	if err != nil {
+		return nil, err
+	}
 	if len(vps) != 0 {
-		for _, vp := range vps {
-			if !vp.IsDeleted() && vp.IsValid() {
-				validPipelines = append(validPipelines, vp.DeepCopy())
+		for i := range vps {
+			vp := vps[i].DeepCopy()
+			if vp.IsDeleted() || !vp.IsValid() {
+				continue
+			}
+			if vp.Spec.DryRun {
+				ok, err := useAppliedSpec(&vp.Spec, vp.Status.AppliedSpec)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+			}
+			selected, err := isSelected(vp, v, namespaceLabels[vp.Namespace])
+			if err != nil {
+				return nil, err
+			}
+			if selected {
+				validPipelines = append(validPipelines, vp)
 			}
 		}
 	}
 	if len(cvps) != 0 {
-		for _, cvp := range cvps {
-			if !cvp.IsDeleted() && cvp.IsValid() {
-				validPipelines = append(validPipelines, cvp.DeepCopy())
+		for i := range cvps {
+			cvp := cvps[i].DeepCopy()
+			if cvp.IsDeleted() || !cvp.IsValid() {
+				continue
+			}
+			if cvp.Spec.DryRun {
+				ok, err := useAppliedSpec(&cvp.Spec, cvp.Status.AppliedSpec)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+			}
+			selected, err := isSelected(cvp, v, nil)
+			if err != nil {
+				return nil, err
+			}
+			if selected {
+				validPipelines = append(validPipelines, cvp)
 			}
 		}
 	}
 	return validPipelines, nil
 }
 
+// useAppliedSpec replaces spec with the last checked spec of pipeline. False is returned, if pipeline was never checked
+func useAppliedSpec(spec *vectorv1alpha1.VectorPipelineSpec, applied *runtime.RawExtension) (bool, error) {
+	if applied == nil {
+		return false, nil
+	}
+	var appliedSpec vectorv1alpha1.VectorPipelineSpec
+	if err := json.Unmarshal(applied.Raw, &appliedSpec); err != nil {
+		return false, err
+	}
+	*spec = appliedSpec
+	return true, nil
+}
+
+// IsSelected returns true, if pipeline matches Vector pipelineSelector and pipelineNamespaceSelector.
+// Namespace selector is not applied to ClusterVectorPipelines
+func IsSelected(ctx context.Context, c client.Client, p Pipeline, v *vectorv1alpha1.Vector) (bool, error) {
+	if v.Spec.PipelineNamespaceSelector == nil || p.GetNamespace() == "" {
+		return isSelected(p, v, nil)
+	}
+
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, client.ObjectKey{Name: p.GetNamespace()}, ns); err != nil {
+		return false, err
+	}
+	return isSelected(p, v, ns.GetLabels())
+}
+
+// isSelected matches pipeline with Vector selectors, namespaceLabels are labels of pipeline namespace
+func isSelected(p Pipeline, v *vectorv1alpha1.Vector, namespaceLabels map[string]string) (bool, error) {
+	match, err := matchLabelSelector(v.Spec.PipelineSelector, p.GetLabels())
+	if err != nil || !match {
+		return false, err
+	}
+
+	if v.Spec.PipelineNamespaceSelector == nil || p.GetNamespace() == "" {
+		return true, nil
+	}
+	return matchLabelSelector(v.Spec.PipelineNamespaceSelector, namespaceLabels)
+}
+
+// getNamespaceLabels returns labels of all namespaces by name, so namespaces are fetched once for all pipelines.
+// Nil is returned, if Vector doesn't select pipelines by namespace
+func getNamespaceLabels(ctx context.Context, c client.Client, v *vectorv1alpha1.Vector) (map[string]map[string]string, error) {
+	if v.Spec.PipelineNamespaceSelector == nil {
+		return nil, nil
+	}
+	list := &corev1.NamespaceList{}
+	if err := c.List(ctx, list); err != nil {
+		return nil, err
+	}
+	namespaceLabels := make(map[string]map[string]string, len(list.Items))
+	for _, ns := range list.Items {
+		namespaceLabels[ns.Name] = ns.Labels
+	}
+	return namespaceLabels, nil
+}
+
+func matchLabelSelector(selector *metav1.LabelSelector, objLabels map[string]string) (bool, error) {
+	if selector == nil {
+		return true, nil
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false, err
+	}
+	return s.Matches(labels.Set(objLabels)), nil
+}
+
+// SetSuccessStatus marks pipeline valid and saves its spec as applied one, so Vectors keep it during dry run
 func SetSuccessStatus(ctx context.Context, client client.Client, p Pipeline) error {
+	spec, err := json.Marshal(p.GetSpec())
+	if err != nil {
+		return err
+	}
+	p.SetAppliedSpec(&runtime.RawExtension{Raw: spec})
 	p.SetConfigCheck(true)
 	p.SetReason(nil)
+	p.SetConfigErrors(nil)
+	p.SetCondition(vectorv1alpha1.ConditionConfigValid, metav1.ConditionTrue, vectorv1alpha1.ReasonConfigCheckPassed, "")
 
 	return p.UpdateStatus(ctx, client)
 }
 
-func SetFailedStatus(ctx context.Context, client client.Client, p Pipeline, reason string) error {
+// SetFailedStatus marks pipeline invalid. conditionReason is machine-readable reason for ConfigValid condition,
+// configErrors are parsed from vector validate output
+func SetFailedStatus(ctx context.Context, client client.Client, p Pipeline, conditionReason, reason string, configErrors ...vectorv1alpha1.ConfigError) error {
 
 	p.SetConfigCheck(false)
 	p.SetReason(&reason)
+	p.SetConfigErrors(configErrors)
+	p.SetCondition(vectorv1alpha1.ConditionConfigValid, metav1.ConditionFalse, conditionReason, reason)
+
+	return p.UpdateStatus(ctx, client)
+}
+
+// SetUnknownStatus sets ConfigValid condition to Unknown, when config check result is not received
+func SetUnknownStatus(ctx context.Context, client client.Client, p Pipeline, conditionReason, message string) error {
+	p.SetCondition(vectorv1alpha1.ConditionConfigValid, metav1.ConditionUnknown, conditionReason, message)
+
+	return p.UpdateStatus(ctx, client)
+}
+
+// SetInfrastructureFailureStatus records, that configcheck pod can't run. Last known validity of pipeline is kept,
+// ConfigValid condition is set to Unknown only for pipeline, that was never checked
+func SetInfrastructureFailureStatus(ctx context.Context, client client.Client, p Pipeline, message string) error {
+	if p.GetConfigCheckResult() != nil {
+		return nil
+	}
+	return SetUnknownStatus(ctx, client, p, vectorv1alpha1.ReasonConfigCheckInfrastructureFailure, message)
+}
+
+// SetDryRunStatus saves configs rendered in dry run mode. Config check is not run for such pipelines
+func SetDryRunStatus(ctx context.Context, client client.Client, p Pipeline, configs []vectorv1alpha1.RenderedConfig) error {
+	p.SetReason(nil)
+	p.SetConfigErrors(nil)
+	p.SetRenderedConfigs(configs)
+	p.SetCondition(vectorv1alpha1.ConditionConfigValid, metav1.ConditionUnknown, vectorv1alpha1.ReasonDryRun, "Pipeline is rendered, but not checked and not applied in dry run mode")
 
 	return p.UpdateStatus(ctx, client)
 }
@@ -0,0 +1,222 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline_test
+
+import (
+	"context"
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/pipeline"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestIsSelected(t *testing.T) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test",
+			Labels: map[string]string{"tenant": "a"},
+		},
+	}
+	vp := &vectorv1alpha1.VectorPipeline{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vp",
+			Namespace: "test",
+			Labels:    map[string]string{"env": "prod"},
+		},
+	}
+	cvp := &vectorv1alpha1.ClusterVectorPipeline{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "cvp",
+			Labels: map[string]string{"env": "prod"},
+		},
+	}
+
+	type testCase struct {
+		name              string
+		pipeline          pipeline.Pipeline
+		selector          *metav1.LabelSelector
+		namespaceSelector *metav1.LabelSelector
+		want              bool
+	}
+
+	cases := []testCase{
+		{
+			name:     "No selectors",
+			pipeline: vp,
+			want:     true,
+		},
+		{
+			name:     "Pipeline selector matches",
+			pipeline: vp,
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			want:     true,
+		},
+		{
+			name:     "Pipeline selector not matches",
+			pipeline: vp,
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+			want:     false,
+		},
+		{
+			name:              "Namespace selector matches",
+			pipeline:          vp,
+			namespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+			want:              true,
+		},
+		{
+			name:              "Namespace selector not matches",
+			pipeline:          vp,
+			namespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "b"}},
+			want:              false,
+		},
+		{
+			name:              "Namespace selector not applied to cluster pipeline",
+			pipeline:          cvp,
+			namespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "b"}},
+			want:              true,
+		},
+		{
+			name:     "Cluster pipeline selector not matches",
+			pipeline: cvp,
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}},
+			want:     false,
+		},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(namespace).Build()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := &vectorv1alpha1.Vector{
+				Spec: vectorv1alpha1.VectorSpec{
+					PipelineSelector:          tc.selector,
+					PipelineNamespaceSelector: tc.namespaceSelector,
+				},
+			}
+			got, err := pipeline.IsSelected(context.Background(), c, tc.pipeline, v)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGetValidPipelines(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, vectorv1alpha1.AddToScheme(scheme))
+
+	valid := true
+	newPipeline := func(name string, dryRun bool) *vectorv1alpha1.VectorPipeline {
+		return &vectorv1alpha1.VectorPipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec:       vectorv1alpha1.VectorPipelineSpec{DryRun: dryRun},
+			Status:     vectorv1alpha1.VectorPipelineStatus{ConfigCheckResult: &valid},
+		}
+	}
+	// Pipeline was checked and applied before dry run was enabled
+	checked := newPipeline("checked-dry-run", false)
+	checked.Spec.Sinks = &runtime.RawExtension{Raw: []byte(`{"sink":{"type":"console","inputs":["source"]}}`)}
+	require.NoError(t, pipeline.SetSuccessStatus(context.Background(), fake.NewClientBuilder().WithScheme(scheme).WithObjects(checked).Build(), checked))
+	appliedSpec := checked.Spec
+	checked.ResourceVersion = ""
+	checked.Spec.DryRun = true
+	checked.Spec.Sinks = &runtime.RawExtension{Raw: []byte(`{"sink":{"type":"blackhole","inputs":["source"]}}`)}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newPipeline("applied", false), newPipeline("dry-run", true), checked).Build()
+
+	pipelines, err := pipeline.GetValidPipelines(context.Background(), c, &vectorv1alpha1.Vector{})
+	require.NoError(t, err)
+	require.Len(t, pipelines, 2)
+	require.Equal(t, "applied", pipelines[0].GetName())
+	require.Equal(t, "checked-dry-run", pipelines[1].GetName())
+	require.Equal(t, appliedSpec, pipelines[1].GetSpec())
+}
+
+func TestGetValidPipelinesNamespaceSelector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, vectorv1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	valid := true
+	newNamespace := func(name, tenant string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"tenant": tenant}}}
+	}
+	newPipeline := func(name, namespace string) *vectorv1alpha1.VectorPipeline {
+		return &vectorv1alpha1.VectorPipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Status:     vectorv1alpha1.VectorPipelineStatus{ConfigCheckResult: &valid},
+		}
+	}
+	cvp := &vectorv1alpha1.ClusterVectorPipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     vectorv1alpha1.VectorPipelineStatus{ConfigCheckResult: &valid},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newNamespace("a", "a"), newNamespace("b", "b"),
+		newPipeline("selected", "a"), newPipeline("not-selected", "b"), cvp,
+	).Build()
+
+	v := &vectorv1alpha1.Vector{
+		Spec: vectorv1alpha1.VectorSpec{
+			PipelineNamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+		},
+	}
+	pipelines, err := pipeline.GetValidPipelines(context.Background(), c, v)
+	require.NoError(t, err)
+	var names []string
+	for _, p := range pipelines {
+		names = append(names, p.GetName())
+	}
+	require.ElementsMatch(t, []string{"selected", "cluster"}, names)
+}
+
+func TestSetInfrastructureFailureStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, vectorv1alpha1.AddToScheme(scheme))
+
+	valid := true
+	checked := &vectorv1alpha1.VectorPipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "checked", Namespace: "test"},
+		Status:     vectorv1alpha1.VectorPipelineStatus{ConfigCheckResult: &valid},
+	}
+	checked.SetCondition(vectorv1alpha1.ConditionConfigValid, metav1.ConditionTrue, vectorv1alpha1.ReasonConfigCheckPassed, "")
+	unchecked := &vectorv1alpha1.VectorPipeline{ObjectMeta: metav1.ObjectMeta{Name: "unchecked", Namespace: "test"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(checked, unchecked).Build()
+	ctx := context.Background()
+
+	for _, p := range []pipeline.Pipeline{checked, unchecked} {
+		require.NoError(t, pipeline.SetInfrastructureFailureStatus(ctx, c, p, "configcheck pod can't run"))
+	}
+
+	got := &vectorv1alpha1.VectorPipeline{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(checked), got))
+	require.True(t, got.IsValid())
+	require.True(t, meta.IsStatusConditionTrue(got.Status.Conditions, vectorv1alpha1.ConditionConfigValid))
+
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(unchecked), got))
+	require.Nil(t, got.Status.ConfigCheckResult)
+	cond := meta.FindStatusCondition(got.Status.Conditions, vectorv1alpha1.ConditionConfigValid)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionUnknown, cond.Status)
+	require.Equal(t, vectorv1alpha1.ReasonConfigCheckInfrastructureFailure, cond.Reason)
+}
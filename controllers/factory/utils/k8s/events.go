@@ -0,0 +1,57 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Reasons of events emitted by operator
+const (
+	EventReasonConfigCheckStarted = "ConfigCheckStarted"
+	EventReasonConfigCheckPassed  = "ConfigCheckPassed"
+	EventReasonConfigCheckFailed  = "ConfigCheckFailed"
+	EventReasonConfigCheckTimeout = "ConfigCheckTimeout"
+	EventReasonConfigApplied      = "ConfigApplied"
+	EventReasonRolloutStarted     = "RolloutStarted"
+	EventReasonPipelineExcluded   = "PipelineExcluded"
+	EventReasonAggregatorDeleted  = "AggregatorDeleted"
+	EventReasonDeprecatedField    = "DeprecatedField"
+	// EventReasonConfigCheckInfrastructureFailure is emitted, when configcheck pod can't run
+	EventReasonConfigCheckInfrastructureFailure = "ConfigCheckInfrastructureFailure"
+)
+
+// MaxEventMessageLength limits event message, config check reasons contain vector validate output
+const MaxEventMessageLength = 1024
+
+// Event emits event on object, if recorder is set. Message is truncated to MaxEventMessageLength
+func Event(recorder record.EventRecorder, obj runtime.Object, eventtype, reason, message string) {
+	if recorder == nil || obj == nil {
+		return
+	}
+	recorder.Event(obj, eventtype, reason, TruncateEventMessage(message))
+}
+
+// TruncateEventMessage cuts message to MaxEventMessageLength
+func TruncateEventMessage(message string) string {
+	const suffix = "..."
+	if len(message) <= MaxEventMessageLength {
+		return message
+	}
+	return message[:MaxEventMessageLength-len(suffix)] + suffix
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestEvent(t *testing.T) {
+	type testCase struct {
+		name    string
+		message string
+		want    string
+	}
+
+	long := strings.Repeat("a", k8s.MaxEventMessageLength+1)
+	cases := []testCase{
+		{
+			name:    "Short message",
+			message: "config check failed",
+			want:    "Warning ConfigCheckFailed config check failed",
+		},
+		{
+			name:    "Long message",
+			message: long,
+			want:    "Warning ConfigCheckFailed " + long[:k8s.MaxEventMessageLength-3] + "...",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(1)
+			k8s.Event(recorder, &corev1.Pod{}, corev1.EventTypeWarning, k8s.EventReasonConfigCheckFailed, tc.message)
+			require.Equal(t, tc.want, <-recorder.Events)
+		})
+	}
+
+	// Nil recorder is allowed for callers without events
+	k8s.Event(nil, &corev1.Pod{}, corev1.EventTypeNormal, k8s.EventReasonConfigApplied, "")
+}
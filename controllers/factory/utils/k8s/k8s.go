@@ -25,10 +25,12 @@ import (
 
 	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/discovery"
@@ -423,6 +425,10 @@ func CreatePod(ctx context.Context, pod *corev1.Pod, c client.Client) error {
 	return err
 }
 
+func CreateJob(ctx context.Context, job *batchv1.Job, c client.Client) error {
+	return c.Create(ctx, job)
+}
+
 func GetPod(ctx context.Context, namespacedName types.NamespacedName, c client.Client) (*corev1.Pod, error) {
 	result := &corev1.Pod{}
 	err := c.Get(ctx, namespacedName, result)
@@ -447,6 +453,15 @@ func DeletePod(ctx context.Context, pod *corev1.Pod, c client.Client) error {
 	return nil
 }
 
+func GetDaemonSet(ctx context.Context, namespacedName types.NamespacedName, c client.Client) (*appsv1.DaemonSet, error) {
+	result := &appsv1.DaemonSet{}
+	err := c.Get(ctx, namespacedName, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func GetSecret(ctx context.Context, namespacedName types.NamespacedName, c client.Client) (*corev1.Secret, error) {
 	result := &corev1.Secret{}
 	err := c.Get(ctx, namespacedName, result)
@@ -472,6 +487,28 @@ func DeleteSecret(ctx context.Context, secret *corev1.Secret, c client.Client) e
 	return nil
 }
 
+// DeleteResource deletes object, it is not an error if object is already deleted
+func DeleteResource(ctx context.Context, obj client.Object, c client.Client) error {
+	return client.IgnoreNotFound(c.Delete(ctx, obj))
+}
+
+// DeleteControlledResource deletes object, if it is controlled by owner. Operator names cluster-scoped objects
+// after namespaced owner, so objects of owners with the same name in other namespaces are kept
+func DeleteControlledResource(ctx context.Context, obj client.Object, owner metav1.Object, c client.Client) error {
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(obj, owner) {
+		return nil
+	}
+	return DeleteResource(ctx, obj, c)
+}
+
+// PodTemplateChanged returns true, if update of workload with desired pod template starts rollout
+func PodTemplateChanged(desired, existing corev1.PodTemplateSpec) bool {
+	return !equality.Semantic.DeepDerivative(desired, existing)
+}
+
 func GetPodLogs(ctx context.Context, pod *corev1.Pod, cs kubernetes.Interface) (string, error) {
 	count := int64(100)
 	podLogOptions := corev1.PodLogOptions{
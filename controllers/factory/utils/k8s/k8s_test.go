@@ -31,8 +31,10 @@ import (
 	api_errors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -938,6 +940,99 @@ func TestDeleteSecret(t *testing.T) {
 	}
 }
 
+func TestDeleteResource(t *testing.T) {
+	deleteResourceCase := func(objInit, obj client.Object, want error) func(t *testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			t.Parallel()
+			req := require.New(t)
+
+			cl := fake.NewClientBuilder().WithObjects(objInit).Build()
+			err := k8s.DeleteResource(context.Background(), obj, cl)
+			req.Equal(want, err)
+			err = cl.Get(context.Background(), client.ObjectKeyFromObject(obj), obj)
+			req.True(api_errors.IsNotFound(err))
+		}
+	}
+
+	cases := []objCase{
+		{
+			name: "Delete ClusterRole",
+			initObj: &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "init"},
+			},
+			obj: &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "init"},
+			},
+			want: nil,
+		},
+		{
+			name: "Delete not exist case",
+			initObj: &corev1.ServiceAccount{
+				ObjectMeta: getInitObjectMeta(),
+			},
+			obj: &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "test-namespace2",
+				},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, deleteResourceCase(tc.initObj, tc.obj, tc.want))
+	}
+}
+
+func TestDeleteControlledResource(t *testing.T) {
+	owner := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "test-namespace", UID: "owner-uid"},
+	}
+	controlledBy := func(uid types.UID) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{Name: "owner", UID: uid, Controller: pointer.BoolPtr(true)}}
+	}
+
+	type testCase struct {
+		name       string
+		initObj    client.Object
+		wantExists bool
+	}
+
+	cases := []testCase{
+		{
+			name: "Controlled by owner",
+			initObj: &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "init", OwnerReferences: controlledBy("owner-uid")},
+			},
+			wantExists: false,
+		},
+		{
+			name: "Controlled by other owner",
+			initObj: &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "init", OwnerReferences: controlledBy("other-uid")},
+			},
+			wantExists: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := require.New(t)
+
+			cl := fake.NewClientBuilder().WithObjects(tc.initObj).Build()
+			err := k8s.DeleteControlledResource(context.Background(), &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "init"}}, owner, cl)
+			req.NoError(err)
+			err = cl.Get(context.Background(), client.ObjectKey{Name: "init"}, &rbacv1.ClusterRole{})
+			req.Equal(tc.wantExists, err == nil)
+		})
+	}
+
+	err := k8s.DeleteControlledResource(context.Background(), &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "missing"}}, owner, fake.NewClientBuilder().Build())
+	require.NoError(t, err)
+}
+
 func TestUpdateStatus(t *testing.T) {
 	updateStatusCase := func(objInit, obj client.Object, want error) func(t *testing.T) {
 		return func(t *testing.T) {
@@ -0,0 +1,278 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package valueref
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/kaasops/vector-operator/controllers/factory/utils/hash"
+	corev1 "k8s.io/api/core/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// EnvPrefix is prefix of env variables with referenced values. Pipelines can't interpolate such variables, and
+	// VectorPipelines can't read env variables with VRL get_env_var, if Vector envAllowlist is set
+	EnvPrefix = "VECTOR_REF_"
+
+	SecretRefKey    = "secretRef"
+	ConfigMapRefKey = "configMapRef"
+
+	SecretKind    = "Secret"
+	ConfigMapKind = "ConfigMap"
+
+	// HashAnnotation is pod template annotation with hash of resolved values
+	HashAnnotation = "observability.kaasops.io/value-refs-hash"
+)
+
+var (
+	ValueRefError         = errors.New("invalid value reference")
+	ValueRefNotFoundError = errors.New("referenced value not found")
+)
+
+// Ref is a key of Secret or ConfigMap referenced from pipeline component options
+type Ref struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Key       string
+}
+
+func (r Ref) String() string {
+	return fmt.Sprintf("%s %s/%s key %s", r.Kind, r.Namespace, r.Name, r.Key)
+}
+
+// EnvName returns name of env variable, that holds referenced value in Vector pods
+func (r Ref) EnvName() string {
+	h := fnv.New64a()
+	h.Write([]byte(strings.Join([]string{r.Kind, r.Namespace, r.Name, r.Key}, "/")))
+	return fmt.Sprintf("%s%016X", EnvPrefix, h.Sum64())
+}
+
+// Replace replaces secretRef and configMapRef placeholders in options with env variables and returns references.
+// namespace is pipeline namespace: VectorPipeline can reference only objects in own namespace,
+// ClusterVectorPipeline (empty namespace) must set namespace in reference
+func Replace(options map[string]interface{}, namespace string) ([]Ref, error) {
+	var refs []Ref
+	for k, v := range options {
+		if err := checkReserved(k); err != nil {
+			return nil, err
+		}
+		value, found, err := replaceValue(v, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		options[k] = value
+		refs = append(refs, found...)
+	}
+	return refs, nil
+}
+
+func replaceValue(v interface{}, namespace string) (interface{}, []Ref, error) {
+	switch value := v.(type) {
+	case string:
+		if err := checkReserved(value); err != nil {
+			return nil, nil, err
+		}
+	case map[string]interface{}:
+		if ref, ok, err := parseRef(value, namespace); ok || err != nil {
+			if err != nil {
+				return nil, nil, err
+			}
+			return "${" + ref.EnvName() + "}", []Ref{ref}, nil
+		}
+		refs, err := Replace(value, namespace)
+		if err != nil {
+			return nil, nil, err
+		}
+		return value, refs, nil
+	case []interface{}:
+		var refs []Ref
+		for i, item := range value {
+			replaced, found, err := replaceValue(item, namespace)
+			if err != nil {
+				return nil, nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			value[i] = replaced
+			refs = append(refs, found...)
+		}
+		return value, refs, nil
+	}
+	return v, nil, nil
+}
+
+// CheckReserved returns error, if any key or string value of component references env variables with EnvPrefix.
+// Vector interpolates whole config file, so fields outside of options (e.g. route conditions and selectors)
+// are checked too
+func CheckReserved(component interface{}) error {
+	switch value := component.(type) {
+	case string:
+		return checkReserved(value)
+	case map[string]interface{}:
+		for k, v := range value {
+			if err := checkReserved(k); err != nil {
+				return err
+			}
+			if err := CheckReserved(v); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			if err := CheckReserved(item); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func checkReserved(s string) error {
+	if strings.Contains(s, EnvPrefix) {
+		return fmt.Errorf("%w: env variables with %s prefix are reserved, use secretRef or configMapRef", ValueRefError, EnvPrefix)
+	}
+	return nil
+}
+
+// parseRef returns reference, if value is {"secretRef": {...}} or {"configMapRef": {...}}
+func parseRef(value map[string]interface{}, namespace string) (Ref, bool, error) {
+	if len(value) != 1 {
+		return Ref{}, false, nil
+	}
+	var ref Ref
+	var spec interface{}
+	if s, ok := value[SecretRefKey]; ok {
+		ref.Kind, spec = SecretKind, s
+	} else if s, ok := value[ConfigMapRefKey]; ok {
+		ref.Kind, spec = ConfigMapKind, s
+	} else {
+		return Ref{}, false, nil
+	}
+
+	fields, ok := spec.(map[string]interface{})
+	if !ok {
+		return Ref{}, true, fmt.Errorf("%w: %s must be an object with name and key", ValueRefError, ref.Kind)
+	}
+	ref.Name, _ = fields["name"].(string)
+	ref.Key, _ = fields["key"].(string)
+	ref.Namespace, _ = fields["namespace"].(string)
+	if ref.Name == "" || ref.Key == "" {
+		return Ref{}, true, fmt.Errorf("%w: %s must have name and key", ValueRefError, ref.Kind)
+	}
+
+	switch {
+	case namespace == "" && ref.Namespace == "":
+		return Ref{}, true, fmt.Errorf("%w: namespace of %s is required in ClusterVectorPipeline", ValueRefError, ref.Kind)
+	case namespace != "" && ref.Namespace == "":
+		ref.Namespace = namespace
+	case namespace != "" && ref.Namespace != namespace:
+		return Ref{}, true, fmt.Errorf("%w: %s from external namespace %s not allowed", ValueRefError, ref.Kind, ref.Namespace)
+	}
+	return ref, true, nil
+}
+
+// Unique returns sorted references without duplicates
+func Unique(refs []Ref) []Ref {
+	seen := make(map[string]Ref, len(refs))
+	for _, ref := range refs {
+		seen[ref.EnvName()] = ref
+	}
+	result := make([]Ref, 0, len(seen))
+	for _, ref := range seen {
+		result = append(result, ref)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].EnvName() < result[j].EnvName()
+	})
+	return result
+}
+
+// Resolve reads referenced values and returns them by env variable names
+func Resolve(ctx context.Context, cs kubernetes.Interface, refs []Ref) (map[string][]byte, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	data := make(map[string][]byte, len(refs))
+	for _, ref := range refs {
+		value, err := get(ctx, cs, ref)
+		if err != nil {
+			return nil, err
+		}
+		data[ref.EnvName()] = value
+	}
+	return data, nil
+}
+
+func get(ctx context.Context, cs kubernetes.Interface, ref Ref) ([]byte, error) {
+	switch ref.Kind {
+	case SecretKind:
+		secret, err := cs.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			if api_errors.IsNotFound(err) {
+				return nil, fmt.Errorf("%w: %s", ValueRefNotFoundError, ref)
+			}
+			return nil, err
+		}
+		if value, ok := secret.Data[ref.Key]; ok {
+			return value, nil
+		}
+	case ConfigMapKind:
+		cm, err := cs.CoreV1().ConfigMaps(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			if api_errors.IsNotFound(err) {
+				return nil, fmt.Errorf("%w: %s", ValueRefNotFoundError, ref)
+			}
+			return nil, err
+		}
+		if value, ok := cm.Data[ref.Key]; ok {
+			return []byte(value), nil
+		}
+		if value, ok := cm.BinaryData[ref.Key]; ok {
+			return value, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ValueRefNotFoundError, ref)
+}
+
+// Hash returns hash of resolved values. It is added to pod template to restart pods, when values are changed
+func Hash(data map[string][]byte) uint32 {
+	// map keys are sorted by json.Marshal, so hash is stable
+	b, _ := json.Marshal(data)
+	return hash.Get(b)
+}
+
+// EnvFrom returns env source for Secret with resolved values. Secret is optional, so pods start,
+// while Secret is removed before workload update
+func EnvFrom(secretName string) []corev1.EnvFromSource {
+	optional := true
+	return []corev1.EnvFromSource{
+		{
+			SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Optional:             &optional,
+			},
+		},
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package valueref_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kaasops/vector-operator/controllers/factory/valueref"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestReplace(t *testing.T) {
+	secretRef := valueref.Ref{Kind: valueref.SecretKind, Namespace: "test", Name: "creds", Key: "password"}
+	configMapRef := valueref.Ref{Kind: valueref.ConfigMapKind, Namespace: "test", Name: "endpoints", Key: "es"}
+
+	type testCase struct {
+		name        string
+		options     string
+		namespace   string
+		wantOptions string
+		wantRefs    []valueref.Ref
+		wantErr     string
+	}
+
+	cases := []testCase{
+		{
+			name:        "No references",
+			options:     `{"endpoint":"http://es:9200","auth":{"strategy":"basic"}}`,
+			namespace:   "test",
+			wantOptions: `{"endpoint":"http://es:9200","auth":{"strategy":"basic"}}`,
+		},
+		{
+			name:        "Nested secretRef",
+			options:     `{"auth":{"password":{"secretRef":{"name":"creds","key":"password"}}}}`,
+			namespace:   "test",
+			wantOptions: `{"auth":{"password":"${` + secretRef.EnvName() + `}"}}`,
+			wantRefs:    []valueref.Ref{secretRef},
+		},
+		{
+			name:        "configMapRef in list",
+			options:     `{"endpoints":[{"configMapRef":{"name":"endpoints","key":"es"}}]}`,
+			namespace:   "test",
+			wantOptions: `{"endpoints":["${` + configMapRef.EnvName() + `}"]}`,
+			wantRefs:    []valueref.Ref{configMapRef},
+		},
+		{
+			name:        "Own namespace",
+			options:     `{"password":{"secretRef":{"name":"creds","key":"password","namespace":"test"}}}`,
+			namespace:   "test",
+			wantOptions: `{"password":"${` + secretRef.EnvName() + `}"}`,
+			wantRefs:    []valueref.Ref{secretRef},
+		},
+		{
+			name:      "External namespace",
+			options:   `{"password":{"secretRef":{"name":"creds","key":"password","namespace":"other"}}}`,
+			namespace: "test",
+			wantErr:   "password: invalid value reference: Secret from external namespace other not allowed",
+		},
+		{
+			name:        "Cluster pipeline with namespace",
+			options:     `{"password":{"secretRef":{"name":"creds","key":"password","namespace":"test"}}}`,
+			wantOptions: `{"password":"${` + secretRef.EnvName() + `}"}`,
+			wantRefs:    []valueref.Ref{secretRef},
+		},
+		{
+			name:    "Cluster pipeline without namespace",
+			options: `{"password":{"secretRef":{"name":"creds","key":"password"}}}`,
+			wantErr: "password: invalid value reference: namespace of Secret is required in ClusterVectorPipeline",
+		},
+		{
+			name:      "Missing key",
+			options:   `{"password":{"secretRef":{"name":"creds"}}}`,
+			namespace: "test",
+			wantErr:   "password: invalid value reference: Secret must have name and key",
+		},
+		{
+			name:      "Reserved env variable",
+			options:   `{"password":"${` + secretRef.EnvName() + `}"}`,
+			namespace: "other",
+			wantErr:   "password: invalid value reference: env variables with VECTOR_REF_ prefix are reserved",
+		},
+		{
+			name:      "Reserved env variable in key",
+			options:   `{"headers":{"${` + secretRef.EnvName() + `}":"x"}}`,
+			namespace: "other",
+			wantErr:   "headers: invalid value reference: env variables with VECTOR_REF_ prefix are reserved",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(tc.options), &options))
+
+			refs, err := valueref.Replace(options, tc.namespace)
+			if tc.wantErr != "" {
+				require.ErrorIs(t, err, valueref.ValueRefError)
+				require.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantRefs, refs)
+			got, err := json.Marshal(options)
+			require.NoError(t, err)
+			require.JSONEq(t, tc.wantOptions, string(got))
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "test"},
+			Data:       map[string][]byte{"password": []byte("secret")},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "endpoints", Namespace: "test"},
+			Data:       map[string]string{"es": "http://es:9200"},
+		},
+	)
+	secretRef := valueref.Ref{Kind: valueref.SecretKind, Namespace: "test", Name: "creds", Key: "password"}
+	configMapRef := valueref.Ref{Kind: valueref.ConfigMapKind, Namespace: "test", Name: "endpoints", Key: "es"}
+
+	data, err := valueref.Resolve(context.Background(), cs, []valueref.Ref{secretRef, configMapRef})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{
+		secretRef.EnvName():    []byte("secret"),
+		configMapRef.EnvName(): []byte("http://es:9200"),
+	}, data)
+
+	_, err = valueref.Resolve(context.Background(), cs, []valueref.Ref{{Kind: valueref.SecretKind, Namespace: "test", Name: "creds", Key: "user"}})
+	require.ErrorIs(t, err, valueref.ValueRefNotFoundError)
+
+	_, err = valueref.Resolve(context.Background(), cs, []valueref.Ref{{Kind: valueref.SecretKind, Namespace: "other", Name: "creds", Key: "password"}})
+	require.ErrorIs(t, err, valueref.ValueRefNotFoundError)
+}
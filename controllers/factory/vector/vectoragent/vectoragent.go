@@ -18,10 +18,15 @@ package vectoragent
 
 import (
 	"context"
+	"fmt"
 
 	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
 	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	"github.com/kaasops/vector-operator/controllers/factory/valueref"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -30,8 +35,13 @@ type Controller struct {
 	Vector *vectorv1alpha1.Vector
 
 	Config []byte
+	// ValueRefs are Secret and ConfigMap keys referenced by config, they are passed to agent as env
+	ValueRefs     []valueref.Ref
+	valueRefsHash string
 	// Temp. Wait this issue - https://github.com/kubernetes-sigs/controller-runtime/issues/452
 	ClientSet *kubernetes.Clientset
+	// Recorder emits events on Vector, events are not emitted if it is nil
+	Recorder record.EventRecorder
 }
 
 func NewController(v *vectorv1alpha1.Vector, c client.Client, cs *kubernetes.Clientset) *Controller {
@@ -46,21 +56,60 @@ func (ctrl *Controller) SetSucceesStatus(ctx context.Context) error {
 	var status = true
 	ctrl.Vector.Status.ConfigCheckResult = &status
 	ctrl.Vector.Status.Reason = nil
+	ctrl.Vector.Status.ConfigErrors = nil
+	ctrl.Vector.SetCondition(vectorv1alpha1.ConditionConfigValid, metav1.ConditionTrue, vectorv1alpha1.ReasonConfigCheckPassed, "")
+	ctrl.Vector.SetCondition(vectorv1alpha1.ConditionApplied, metav1.ConditionTrue, vectorv1alpha1.ReasonConfigApplied, "")
 
 	return k8s.UpdateStatus(ctx, ctrl.Vector, ctrl.Client)
 }
 
-func (ctrl *Controller) SetFailedStatus(ctx context.Context, reason string) error {
+// SetFailedStatus marks Vector config invalid. conditionReason is machine-readable reason for ConfigValid condition.
+// Vector keeps working with last applied config, so it is marked Degraded. configErrors are parsed from vector validate output
+func (ctrl *Controller) SetFailedStatus(ctx context.Context, conditionReason, reason string, configErrors ...vectorv1alpha1.ConfigError) error {
 	var status = false
 	ctrl.Vector.Status.ConfigCheckResult = &status
 	ctrl.Vector.Status.Reason = &reason
+	ctrl.Vector.Status.ConfigErrors = configErrors
+	ctrl.Vector.SetCondition(vectorv1alpha1.ConditionConfigValid, metav1.ConditionFalse, conditionReason, reason)
+	ctrl.Vector.SetCondition(vectorv1alpha1.ConditionDegraded, metav1.ConditionTrue, conditionReason, "Last valid config is used")
+	ctrl.event(corev1.EventTypeWarning, k8s.EventReasonConfigCheckFailed, fmt.Sprintf("%s: %s", conditionReason, reason))
 
 	return k8s.UpdateStatus(ctx, ctrl.Vector, ctrl.Client)
 }
 
+// SetUnknownStatus sets ConfigValid condition to Unknown, when config check result is not received
+func (ctrl *Controller) SetUnknownStatus(ctx context.Context, conditionReason, message string) error {
+	ctrl.Vector.SetCondition(vectorv1alpha1.ConditionConfigValid, metav1.ConditionUnknown, conditionReason, message)
+
+	return k8s.UpdateStatus(ctx, ctrl.Vector, ctrl.Client)
+}
+
+// SetInfrastructureFailureStatus records, that configcheck pod can't run. Last known validity of Vector config is kept,
+// ConfigValid condition is set to Unknown only for Vector, that was never checked
+func (ctrl *Controller) SetInfrastructureFailureStatus(ctx context.Context, message string) error {
+	if ctrl.Vector.Status.ConfigCheckResult != nil {
+		return nil
+	}
+	return ctrl.SetUnknownStatus(ctx, vectorv1alpha1.ReasonConfigCheckInfrastructureFailure, message)
+}
+
 func (ctrl *Controller) SetLastAppliedPipelineStatus(ctx context.Context, hash *uint32) error {
+	if hash != nil && (ctrl.Vector.Status.LastAppliedConfigHash == nil || *ctrl.Vector.Status.LastAppliedConfigHash != *hash) {
+		ctrl.event(corev1.EventTypeNormal, k8s.EventReasonConfigApplied, fmt.Sprintf("Vector Agent config %d applied", *hash))
+	}
 
 	ctrl.Vector.Status.LastAppliedConfigHash = hash
 
 	return k8s.UpdateStatus(ctx, ctrl.Vector, ctrl.Client)
 }
+
+// SetExcludedPipelinesStatus records pipelines, that are excluded from configs of Vector until they are changed
+func (ctrl *Controller) SetExcludedPipelinesStatus(ctx context.Context, exclusions []vectorv1alpha1.ExcludedPipeline) error {
+	ctrl.Vector.Status.ExcludedPipelines = exclusions
+
+	return k8s.UpdateStatus(ctx, ctrl.Vector, ctrl.Client)
+}
+
+func (ctrl *Controller) event(eventtype, reason, message string) {
+	k8s.Event(ctrl.Recorder, ctrl.Vector, eventtype, reason, message)
+}
@@ -18,11 +18,16 @@ package vectoragent
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
 	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -35,6 +40,9 @@ func (ctrl *Controller) EnsureVectorAgent(ctx context.Context, configOnly bool)
 		return err
 	}
 
+	if err := ctrl.ensureVectorAgentEnv(ctx); err != nil {
+		return err
+	}
 	if err := ctrl.ensureVectorAgentConfig(ctx); err != nil {
 		return err
 	}
@@ -98,6 +106,19 @@ func (ctrl *Controller) ensureVectorAgentClusterRoleBinding(ctx context.Context)
 	return k8s.CreateOrUpdateResource(ctx, vectorAgentClusterRoleBinding, ctrl.Client)
 }
 
+// DeleteVectorAgentClusterRBAC removes Vector Agent ClusterRole and ClusterRoleBinding. Cluster-scoped objects
+// are not garbage-collected by namespaced owner reference, so they are removed on Vector deletion
+func (ctrl *Controller) DeleteVectorAgentClusterRBAC(ctx context.Context) error {
+	log := log.FromContext(ctx).WithValues("vector-agent-rbac", ctrl.Vector.Name)
+
+	log.Info("start Delete Vector Agent cluster RBAC")
+
+	if err := k8s.DeleteControlledResource(ctx, ctrl.createVectorAgentClusterRoleBinding(), ctrl.Vector, ctrl.Client); err != nil {
+		return err
+	}
+	return k8s.DeleteControlledResource(ctx, ctrl.createVectorAgentClusterRole(), ctrl.Vector, ctrl.Client)
+}
+
 func (ctrl *Controller) ensureVectorAgentService(ctx context.Context) error {
 	log := log.FromContext(ctx).WithValues("vector-agent-service", ctrl.Vector.Name)
 
@@ -126,9 +147,27 @@ func (ctrl *Controller) ensureVectorAgentDaemonSet(ctx context.Context) error {
 
 	log.Info("start Reconcile Vector Agent DaemonSet")
 
+	if ctrl.usesPriorityClassFallback() {
+		log.Info("Deprecated podSecurityPolicyName is used as priority class of agent pods, move it to priorityClassName")
+		ctrl.event(corev1.EventTypeWarning, k8s.EventReasonDeprecatedField, fmt.Sprintf("podSecurityPolicyName %s is used as priority class of agent pods, move it to priorityClassName", ctrl.Vector.Spec.Agent.PodSecurityPolicyName))
+	}
+
 	vectorAgentDaemonSet := ctrl.createVectorAgentDaemonSet()
 
-	return k8s.CreateOrUpdateResource(ctx, vectorAgentDaemonSet, ctrl.Client)
+	existing := &appsv1.DaemonSet{}
+	if err := ctrl.Get(ctx, client.ObjectKeyFromObject(vectorAgentDaemonSet), existing); err != nil {
+		if !api_errors.IsNotFound(err) {
+			return err
+		}
+		existing = nil
+	}
+	if err := k8s.CreateOrUpdateResource(ctx, vectorAgentDaemonSet, ctrl.Client); err != nil {
+		return err
+	}
+	if existing == nil || k8s.PodTemplateChanged(vectorAgentDaemonSet.Spec.Template, existing.Spec.Template) {
+		ctrl.event(corev1.EventTypeNormal, k8s.EventReasonRolloutStarted, fmt.Sprintf("Vector Agent DaemonSet %s rollout started", vectorAgentDaemonSet.Name))
+	}
+	return nil
 }
 
 func (ctrl *Controller) ensureVectorAgentPodMonitor(ctx context.Context) error {
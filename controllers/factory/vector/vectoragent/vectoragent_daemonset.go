@@ -17,6 +17,7 @@ limitations under the License.
 package vectoragent
 
 import (
+	"github.com/kaasops/vector-operator/controllers/factory/valueref"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -51,7 +52,7 @@ func (ctrl *Controller) createVectorAgentDaemonSet() *appsv1.DaemonSet {
 					RuntimeClassName:   ctrl.Vector.Spec.Agent.RuntimeClassName,
 					SchedulerName:      ctrl.Vector.Spec.Agent.SchedulerName,
 					Tolerations:        ctrl.Vector.Spec.Agent.Tolerations,
-					PriorityClassName:  ctrl.Vector.Spec.Agent.PodSecurityPolicyName,
+					PriorityClassName:  ctrl.getPriorityClassName(),
 					HostNetwork:        ctrl.Vector.Spec.Agent.HostNetwork,
 					HostAliases:        ctrl.Vector.Spec.Agent.HostAliases,
 					InitContainers:     initContainers,
@@ -61,9 +62,29 @@ func (ctrl *Controller) createVectorAgentDaemonSet() *appsv1.DaemonSet {
 		},
 	}
 
+	if ctrl.valueRefsHash != "" {
+		daemonset.Spec.Template.Annotations = map[string]string{
+			valueref.HashAnnotation: ctrl.valueRefsHash,
+		}
+	}
+
 	return daemonset
 }
 
+// getPriorityClassName returns priorityClassName of agent. Earlier versions set podSecurityPolicyName as priority
+// class of agent pods, so it is used, if priorityClassName is empty, to keep pods of existing Vectors unchanged
+func (ctrl *Controller) getPriorityClassName() string {
+	if ctrl.usesPriorityClassFallback() {
+		return ctrl.Vector.Spec.Agent.PodSecurityPolicyName
+	}
+	return ctrl.Vector.Spec.Agent.PriorityClassName
+}
+
+// usesPriorityClassFallback returns true, if deprecated podSecurityPolicyName is used as priority class of agent pods
+func (ctrl *Controller) usesPriorityClassFallback() bool {
+	return ctrl.Vector.Spec.Agent.PriorityClassName == "" && ctrl.Vector.Spec.Agent.PodSecurityPolicyName != ""
+}
+
 func (ctrl *Controller) generateVectorAgentVolume() []corev1.Volume {
 	volume := ctrl.Vector.Spec.Agent.Volumes
 	configVolumeSource := corev1.VolumeSource{
@@ -206,7 +227,8 @@ func (ctrl *Controller) VectorAgentContainer() *corev1.Container {
 		Image: ctrl.Vector.Spec.Agent.Image,
 		Args:  []string{"--config-dir", "/etc/vector", "--watch-config"},
 		// Command: []string{"/bin/sleep", "1000000000000000"},
-		Env: ctrl.generateVectorAgentEnvs(),
+		Env:     ctrl.generateVectorAgentEnvs(),
+		EnvFrom: ctrl.generateVectorAgentEnvFrom(),
 		Ports: []corev1.ContainerPort{
 			{
 				Name:          "prom-exporter",
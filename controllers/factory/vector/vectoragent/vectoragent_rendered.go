@@ -0,0 +1,86 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectoragent
+
+import (
+	"context"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	corev1 "k8s.io/api/core/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// EnsureRenderedConfig saves uncompressed configs, rendered in dry run mode, to <name>-rendered-config Secret
+func (ctrl *Controller) EnsureRenderedConfig(ctx context.Context, agentConfig, aggregatorConfig []byte) error {
+	log := log.FromContext(ctx).WithValues("vector-rendered-config", ctrl.Vector.Name)
+
+	log.Info("start Reconcile Vector rendered config Secret")
+
+	labels := ctrl.labelsForVectorAgent()
+	labels[k8s.ComponentLabelKey] = "RenderedConfig"
+	data := map[string][]byte{
+		"agent.json": agentConfig,
+	}
+	if aggregatorConfig != nil {
+		data["aggregator.json"] = aggregatorConfig
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            ctrl.getNameRenderedConfig(),
+			Namespace:       ctrl.Vector.Namespace,
+			Labels:          labels,
+			OwnerReferences: ctrl.getControllerReference(),
+		},
+		Data: data,
+	}
+
+	return k8s.CreateOrUpdateResource(ctx, secret, ctrl.Client)
+}
+
+// SetDryRunStatus marks Vector config rendered, but not checked and not applied
+func (ctrl *Controller) SetDryRunStatus(ctx context.Context) error {
+	ctrl.Vector.Status.Reason = nil
+	ctrl.Vector.Status.ConfigErrors = nil
+	ctrl.Vector.SetCondition(vectorv1alpha1.ConditionConfigValid, metav1.ConditionUnknown, vectorv1alpha1.ReasonDryRun, "Config check is not run in dry run mode")
+	ctrl.Vector.SetCondition(vectorv1alpha1.ConditionApplied, metav1.ConditionFalse, vectorv1alpha1.ReasonDryRun, "Config is rendered to "+ctrl.getNameRenderedConfig()+" Secret, but not applied")
+
+	return k8s.UpdateStatus(ctx, ctrl.Vector, ctrl.Client)
+}
+
+// DeleteRenderedConfig removes Secret with rendered configs, when dry run mode is disabled
+func (ctrl *Controller) DeleteRenderedConfig(ctx context.Context) error {
+	nn := types.NamespacedName{
+		Name:      ctrl.getNameRenderedConfig(),
+		Namespace: ctrl.Vector.Namespace,
+	}
+	secret, err := k8s.GetSecret(ctx, nn, ctrl.Client)
+	if err != nil {
+		if api_errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return k8s.DeleteSecret(ctx, secret, ctrl.Client)
+}
+
+func (ctrl *Controller) getNameRenderedConfig() string {
+	return ctrl.Vector.Name + "-rendered-config"
+}
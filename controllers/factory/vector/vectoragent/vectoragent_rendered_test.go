@@ -0,0 +1,53 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectoragent_test
+
+import (
+	"context"
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoragent"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRenderedConfig(t *testing.T) {
+	ctx := context.Background()
+	v := &vectorv1alpha1.Vector{
+		ObjectMeta: metav1.ObjectMeta{Name: "vector", Namespace: "test"},
+	}
+	c := fake.NewClientBuilder().Build()
+	vaCtrl := vectoragent.NewController(v, c, nil)
+	nn := types.NamespacedName{Name: "vector-rendered-config", Namespace: "test"}
+
+	// Nothing to delete before dry run
+	require.NoError(t, vaCtrl.DeleteRenderedConfig(ctx))
+
+	require.NoError(t, vaCtrl.EnsureRenderedConfig(ctx, []byte(`{"agent":true}`), []byte(`{"aggregator":true}`)))
+	secret := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, nn, secret))
+	require.Equal(t, `{"agent":true}`, string(secret.Data["agent.json"]))
+	require.Equal(t, `{"aggregator":true}`, string(secret.Data["aggregator.json"]))
+
+	require.NoError(t, vaCtrl.DeleteRenderedConfig(ctx))
+	require.True(t, api_errors.IsNotFound(c.Get(ctx, nn, secret)))
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectoragent
+
+import (
+	"context"
+	"fmt"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const CrashLoopBackOffReason = "CrashLoopBackOff"
+
+// SetAgentStatus sets Vector Agent rollout status, AgentReady and Degraded conditions
+// from Vector Agent DaemonSet and pods. Status is not updated, it is saved with next status update
+func (ctrl *Controller) SetAgentStatus(ctx context.Context) error {
+	ds, err := k8s.GetDaemonSet(ctx, types.NamespacedName{Name: ctrl.getNameVectorAgent(), Namespace: ctrl.Vector.Namespace}, ctrl.Client)
+	if err != nil {
+		return err
+	}
+
+	ctrl.Vector.Status.Agent = &vectorv1alpha1.VectorAgentStatus{
+		DesiredNumberScheduled: ds.Status.DesiredNumberScheduled,
+		NumberReady:            ds.Status.NumberReady,
+		UpdatedNumberScheduled: ds.Status.UpdatedNumberScheduled,
+		NumberUnavailable:      ds.Status.NumberUnavailable,
+	}
+	setAgentReadyCondition(ctrl.Vector, ds)
+
+	pods := corev1.PodList{}
+	if err := ctrl.List(ctx, &pods, client.InNamespace(ctrl.Vector.Namespace), client.MatchingLabels(ctrl.labelsForVectorAgent())); err != nil {
+		return err
+	}
+	if message := getCrashLoopMessage(pods.Items); message != "" {
+		ctrl.Vector.SetCondition(vectorv1alpha1.ConditionDegraded, metav1.ConditionTrue, vectorv1alpha1.ReasonCrashLoopBackOff, message)
+		return nil
+	}
+	ctrl.Vector.SetCondition(vectorv1alpha1.ConditionDegraded, metav1.ConditionFalse, vectorv1alpha1.ReasonAsExpected, "")
+	return nil
+}
+
+func setAgentReadyCondition(v *vectorv1alpha1.Vector, ds *appsv1.DaemonSet) {
+	message := fmt.Sprintf("%d/%d pods ready, %d updated", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled, ds.Status.UpdatedNumberScheduled)
+	switch {
+	case ds.Status.ObservedGeneration != ds.Generation || ds.Status.UpdatedNumberScheduled != ds.Status.DesiredNumberScheduled:
+		v.SetCondition(vectorv1alpha1.ConditionAgentReady, metav1.ConditionFalse, vectorv1alpha1.ReasonRolloutInProgress, message)
+	case ds.Status.NumberReady != ds.Status.DesiredNumberScheduled:
+		v.SetCondition(vectorv1alpha1.ConditionAgentReady, metav1.ConditionFalse, vectorv1alpha1.ReasonPodsNotReady, message)
+	default:
+		v.SetCondition(vectorv1alpha1.ConditionAgentReady, metav1.ConditionTrue, vectorv1alpha1.ReasonPodsReady, message)
+	}
+}
+
+// getCrashLoopMessage returns description of the first crash-looping container with its last termination message,
+// or empty string if there are no crash-looping containers
+func getCrashLoopMessage(pods []corev1.Pod) string {
+	for _, pod := range pods {
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.State.Waiting == nil || cs.State.Waiting.Reason != CrashLoopBackOffReason {
+				continue
+			}
+			message := fmt.Sprintf("container %s in pod %s is in CrashLoopBackOff, restarts: %d", cs.Name, pod.Name, cs.RestartCount)
+			if t := cs.LastTerminationState.Terminated; t != nil {
+				message += fmt.Sprintf(", last exit code: %d", t.ExitCode)
+				if t.Message != "" {
+					message += ", last termination message: " + t.Message
+				}
+			}
+			return message
+		}
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectoragent_test
+
+import (
+	"context"
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoragent"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSetAgentStatus(t *testing.T) {
+	agentLabels := map[string]string{
+		k8s.ManagedByLabelKey: "vector-operator",
+		k8s.NameLabelKey:      "vector",
+		k8s.ComponentLabelKey: "Agent",
+		k8s.InstanceLabelKey:  "vector",
+	}
+	ds := func(desired, ready, updated int32) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "vector-agent", Namespace: "test"},
+			Status: appsv1.DaemonSetStatus{
+				DesiredNumberScheduled: desired,
+				NumberReady:            ready,
+				UpdatedNumberScheduled: updated,
+				NumberUnavailable:      desired - ready,
+			},
+		}
+	}
+	crashLoopPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "vector-agent-abcde", Namespace: "test", Labels: agentLabels},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "vector-agent",
+					RestartCount: 5,
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: vectoragent.CrashLoopBackOffReason},
+					},
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{ExitCode: 78, Message: "Configuration error."},
+					},
+				},
+			},
+		},
+	}
+
+	type testCase struct {
+		name           string
+		objects        []client.Object
+		wantReady      metav1.ConditionStatus
+		wantReadyWhy   string
+		wantDegraded   metav1.ConditionStatus
+		wantDegradedIn string
+	}
+
+	cases := []testCase{
+		{
+			name:         "All pods ready",
+			objects:      []client.Object{ds(2, 2, 2)},
+			wantReady:    metav1.ConditionTrue,
+			wantReadyWhy: vectorv1alpha1.ReasonPodsReady,
+			wantDegraded: metav1.ConditionFalse,
+		},
+		{
+			name:         "Rollout in progress",
+			objects:      []client.Object{ds(2, 2, 1)},
+			wantReady:    metav1.ConditionFalse,
+			wantReadyWhy: vectorv1alpha1.ReasonRolloutInProgress,
+			wantDegraded: metav1.ConditionFalse,
+		},
+		{
+			name:           "Crash-looping pod",
+			objects:        []client.Object{ds(2, 1, 2), crashLoopPod},
+			wantReady:      metav1.ConditionFalse,
+			wantReadyWhy:   vectorv1alpha1.ReasonPodsNotReady,
+			wantDegraded:   metav1.ConditionTrue,
+			wantDegradedIn: "Configuration error.",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := &vectorv1alpha1.Vector{
+				ObjectMeta: metav1.ObjectMeta{Name: "vector", Namespace: "test"},
+			}
+			c := fake.NewClientBuilder().WithObjects(tc.objects...).Build()
+			vaCtrl := vectoragent.NewController(v, c, nil)
+
+			require.NoError(t, vaCtrl.SetAgentStatus(context.Background()))
+			require.Equal(t, int32(2), v.Status.Agent.DesiredNumberScheduled)
+
+			ready := meta.FindStatusCondition(v.Status.Conditions, vectorv1alpha1.ConditionAgentReady)
+			require.Equal(t, tc.wantReady, ready.Status)
+			require.Equal(t, tc.wantReadyWhy, ready.Reason)
+
+			degraded := meta.FindStatusCondition(v.Status.Conditions, vectorv1alpha1.ConditionDegraded)
+			require.Equal(t, tc.wantDegraded, degraded.Status)
+			require.Contains(t, degraded.Message, tc.wantDegradedIn)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectoragent_test
+
+import (
+	"context"
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoragent"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestStatusEvents(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, vectorv1alpha1.AddToScheme(scheme))
+	v := &vectorv1alpha1.Vector{
+		ObjectMeta: metav1.ObjectMeta{Name: "vector", Namespace: "test"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(v).Build()
+	recorder := record.NewFakeRecorder(10)
+	vaCtrl := vectoragent.NewController(v, c, nil)
+	vaCtrl.Recorder = recorder
+
+	hash := uint32(42)
+	require.NoError(t, vaCtrl.SetLastAppliedPipelineStatus(ctx, &hash))
+	require.Equal(t, "Normal ConfigApplied Vector Agent config 42 applied", <-recorder.Events)
+
+	// Config is not changed
+	require.NoError(t, vaCtrl.SetLastAppliedPipelineStatus(ctx, &hash))
+	require.Empty(t, recorder.Events)
+
+	require.NoError(t, vaCtrl.SetFailedStatus(ctx, vectorv1alpha1.ReasonValidationFailed, "invalid sink"))
+	require.Equal(t, "Warning ConfigCheckFailed ValidationFailed: invalid sink", <-recorder.Events)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectoraggregator
+
+import (
+	"context"
+	"fmt"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	"github.com/kaasops/vector-operator/controllers/factory/valueref"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type Controller struct {
+	client.Client
+	Vector *vectorv1alpha1.Vector
+
+	Config []byte
+	// ValueRefs are Secret and ConfigMap keys referenced by config, they are passed to aggregator as env
+	ValueRefs     []valueref.Ref
+	valueRefsHash string
+	// Temp. Wait this issue - https://github.com/kubernetes-sigs/controller-runtime/issues/452
+	ClientSet *kubernetes.Clientset
+	// Recorder emits events on Vector, events are not emitted if it is nil
+	Recorder record.EventRecorder
+}
+
+func NewController(v *vectorv1alpha1.Vector, c client.Client, cs *kubernetes.Clientset) *Controller {
+	return &Controller{
+		Client:    c,
+		Vector:    v,
+		ClientSet: cs,
+	}
+}
+
+func (ctrl *Controller) SetLastAppliedConfigStatus(ctx context.Context, hash *uint32) error {
+	if hash != nil && (ctrl.Vector.Status.LastAppliedAggregatorConfigHash == nil || *ctrl.Vector.Status.LastAppliedAggregatorConfigHash != *hash) {
+		ctrl.event(corev1.EventTypeNormal, k8s.EventReasonConfigApplied, fmt.Sprintf("Vector Aggregator config %d applied", *hash))
+	}
+
+	ctrl.Vector.Status.LastAppliedAggregatorConfigHash = hash
+
+	return k8s.UpdateStatus(ctx, ctrl.Vector, ctrl.Client)
+}
+
+func (ctrl *Controller) event(eventtype, reason, message string) {
+	k8s.Event(ctrl.Recorder, ctrl.Vector, eventtype, reason, message)
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectoraggregator
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+func (ctrl *Controller) createVectorAggregatorConfig() *corev1.Secret {
+	labels := ctrl.labelsForVectorAggregator()
+	config := map[string][]byte{
+		"aggregator.json": ctrl.Config,
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: ctrl.objectMetaVectorAggregator(labels),
+		Data:       config,
+	}
+
+	return secret
+}
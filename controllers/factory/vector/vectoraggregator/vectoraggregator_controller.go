@@ -0,0 +1,218 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectoraggregator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	api_meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func (ctrl *Controller) EnsureVectorAggregator(ctx context.Context, configOnly bool) error {
+	log := log.FromContext(ctx).WithValues("vector-aggregator", ctrl.Vector.Name)
+	log.Info("start Reconcile Vector Aggregator")
+
+	monitoringCRD, err := k8s.ResourceExists(ctrl.ClientSet.Discovery(), monitorv1.SchemeGroupVersion.String(), monitorv1.PodMonitorsKind)
+	if err != nil {
+		return err
+	}
+
+	if err := ctrl.ensureVectorAggregatorEnv(ctx); err != nil {
+		return err
+	}
+	if err := ctrl.ensureVectorAggregatorConfig(ctx); err != nil {
+		return err
+	}
+	if !configOnly {
+		if err := ctrl.ensureVectorAggregatorServiceAccount(ctx); err != nil {
+			return err
+		}
+
+		if err := ctrl.ensureVectorAggregatorService(ctx); err != nil {
+			return err
+		}
+
+		if ctrl.Vector.Spec.Aggregator.InternalMetrics && monitoringCRD {
+			if err := ctrl.ensureVectorAggregatorPodMonitor(ctx); err != nil {
+				return err
+			}
+		}
+
+		if err := ctrl.ensureVectorAggregatorStatefulSet(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureVectorAggregatorServiceAccount creates ServiceAccount of Vector Aggregator pods. Aggregator receives events
+// from agents and doesn't read Kubernetes API, so ServiceAccount has no roles
+func (ctrl *Controller) ensureVectorAggregatorServiceAccount(ctx context.Context) error {
+	log := log.FromContext(ctx).WithValues("vector-aggregator-serviceaccount", ctrl.Vector.Name)
+
+	log.Info("start Reconcile Vector Aggregator ServiceAccount")
+
+	vectorAggregatorServiceAccount := ctrl.createVectorAggregatorServiceAccount()
+
+	return k8s.CreateOrUpdateResource(ctx, vectorAggregatorServiceAccount, ctrl.Client)
+}
+
+// DeleteVectorAggregator removes Vector Aggregator resources, when aggregator is disabled for Vector.
+// PersistentVolumeClaims of data dir are kept, like on StatefulSet scale down
+func (ctrl *Controller) DeleteVectorAggregator(ctx context.Context) error {
+	log := log.FromContext(ctx).WithValues("vector-aggregator", ctrl.Vector.Name)
+
+	meta := ctrl.objectMetaVectorAggregator(ctrl.labelsForVectorAggregator())
+	objects := []client.Object{
+		&appsv1.StatefulSet{ObjectMeta: meta},
+		&corev1.Service{ObjectMeta: meta},
+		&corev1.Secret{ObjectMeta: meta},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: ctrl.getNameVectorAggregatorEnv(), Namespace: ctrl.Vector.Namespace}},
+		&corev1.ServiceAccount{ObjectMeta: meta},
+		&monitorv1.PodMonitor{ObjectMeta: meta},
+	}
+
+	deleted := false
+	for _, obj := range objects {
+		if err := k8s.DeleteControlledResource(ctx, obj, ctrl.Vector, ctrl.Client); err != nil {
+			// PodMonitor CRD is not installed
+			if api_meta.IsNoMatchError(err) {
+				continue
+			}
+			return err
+		}
+		// Object is fetched by DeleteControlledResource, if it exists
+		if obj.GetResourceVersion() != "" && metav1.IsControlledBy(obj, ctrl.Vector) {
+			deleted = true
+		}
+	}
+	if deleted {
+		log.Info("Vector Aggregator is disabled, resources are deleted")
+		ctrl.event(corev1.EventTypeNormal, k8s.EventReasonAggregatorDeleted, fmt.Sprintf("Vector Aggregator %s is disabled, resources are deleted", ctrl.getNameVectorAggregator()))
+	}
+	if ctrl.Vector.Status.LastAppliedAggregatorConfigHash == nil {
+		return nil
+	}
+	return ctrl.SetLastAppliedConfigStatus(ctx, nil)
+}
+
+func (ctrl *Controller) ensureVectorAggregatorService(ctx context.Context) error {
+	log := log.FromContext(ctx).WithValues("vector-aggregator-service", ctrl.Vector.Name)
+
+	log.Info("start Reconcile Vector Aggregator Service")
+
+	vectorAggregatorService := ctrl.createVectorAggregatorService()
+
+	return k8s.CreateOrUpdateResource(ctx, vectorAggregatorService, ctrl.Client)
+}
+
+func (ctrl *Controller) ensureVectorAggregatorConfig(ctx context.Context) error {
+	log := log.FromContext(ctx).WithValues("vector-aggregator-secret", ctrl.Vector.Name)
+
+	log.Info("start Reconcile Vector Aggregator Secret")
+
+	vectorAggregatorSecret := ctrl.createVectorAggregatorConfig()
+
+	return k8s.CreateOrUpdateResource(ctx, vectorAggregatorSecret, ctrl.Client)
+}
+
+func (ctrl *Controller) ensureVectorAggregatorStatefulSet(ctx context.Context) error {
+	log := log.FromContext(ctx).WithValues("vector-aggregator-statefulset", ctrl.Vector.Name)
+
+	log.Info("start Reconcile Vector Aggregator StatefulSet")
+
+	vectorAggregatorStatefulSet := ctrl.createVectorAggregatorStatefulSet()
+
+	existing := &appsv1.StatefulSet{}
+	if err := ctrl.Get(ctx, client.ObjectKeyFromObject(vectorAggregatorStatefulSet), existing); err != nil {
+		if !api_errors.IsNotFound(err) {
+			return err
+		}
+		existing = nil
+	}
+	// volumeClaimTemplates of StatefulSet can't be updated, StatefulSet is recreated on the next reconcile
+	if existing != nil && volumeClaimTemplatesChanged(vectorAggregatorStatefulSet.Spec.VolumeClaimTemplates, existing.Spec.VolumeClaimTemplates) {
+		log.Info("Vector Aggregator data volume claim template is changed, delete StatefulSet")
+		if err := k8s.DeleteResource(ctx, existing, ctrl.Client); err != nil {
+			return err
+		}
+		return fmt.Errorf("Vector Aggregator StatefulSet %s is recreated to change data volume claim template", existing.Name)
+	}
+	if err := k8s.CreateOrUpdateResource(ctx, vectorAggregatorStatefulSet, ctrl.Client); err != nil {
+		return err
+	}
+	if existing == nil || k8s.PodTemplateChanged(vectorAggregatorStatefulSet.Spec.Template, existing.Spec.Template) {
+		ctrl.event(corev1.EventTypeNormal, k8s.EventReasonRolloutStarted, fmt.Sprintf("Vector Aggregator StatefulSet %s rollout started", vectorAggregatorStatefulSet.Name))
+	}
+	return nil
+}
+
+func (ctrl *Controller) ensureVectorAggregatorPodMonitor(ctx context.Context) error {
+	log := log.FromContext(ctx).WithValues("vector-aggregator-podmonitor", ctrl.Vector.Name)
+
+	log.Info("start Reconcile Vector Aggregator PodMonitor")
+
+	vectorAggregatorPodMonitor := ctrl.createVectorAggregatorPodMonitor()
+
+	return k8s.CreateOrUpdateResource(ctx, vectorAggregatorPodMonitor, ctrl.Client)
+}
+
+func (ctrl *Controller) labelsForVectorAggregator() map[string]string {
+	return map[string]string{
+		k8s.ManagedByLabelKey: "vector-operator",
+		k8s.NameLabelKey:      "vector",
+		k8s.ComponentLabelKey: "Aggregator",
+		k8s.InstanceLabelKey:  ctrl.Vector.Name,
+	}
+}
+
+func (ctrl *Controller) objectMetaVectorAggregator(labels map[string]string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:            ctrl.getNameVectorAggregator(),
+		Namespace:       ctrl.Vector.Namespace,
+		Labels:          labels,
+		OwnerReferences: ctrl.getControllerReference(),
+	}
+}
+
+func (ctrl *Controller) getNameVectorAggregator() string {
+	name := ctrl.Vector.Name + "-aggregator"
+	return name
+}
+
+func (ctrl *Controller) getControllerReference() []metav1.OwnerReference {
+	return []metav1.OwnerReference{
+		{
+			APIVersion:         ctrl.Vector.APIVersion,
+			Kind:               ctrl.Vector.Kind,
+			Name:               ctrl.Vector.GetName(),
+			UID:                ctrl.Vector.GetUID(),
+			BlockOwnerDeletion: pointer.BoolPtr(true),
+			Controller:         pointer.BoolPtr(true),
+		},
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectoraggregator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	"github.com/kaasops/vector-operator/controllers/factory/valueref"
+	corev1 "k8s.io/api/core/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ensureVectorAggregatorEnv saves values referenced by pipelines to <name>-aggregator-env Secret.
+// Config Secret contains only env variables names
+func (ctrl *Controller) ensureVectorAggregatorEnv(ctx context.Context) error {
+	log := log.FromContext(ctx).WithValues("vector-aggregator-env", ctrl.Vector.Name)
+
+	log.Info("start Reconcile Vector Aggregator env Secret")
+
+	data, err := valueref.Resolve(ctx, ctrl.ClientSet, ctrl.ValueRefs)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		ctrl.valueRefsHash = ""
+		return ctrl.deleteVectorAggregatorEnv(ctx)
+	}
+	ctrl.valueRefsHash = fmt.Sprint(valueref.Hash(data))
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            ctrl.getNameVectorAggregatorEnv(),
+			Namespace:       ctrl.Vector.Namespace,
+			Labels:          ctrl.labelsForVectorAggregator(),
+			OwnerReferences: ctrl.getControllerReference(),
+		},
+		Data: data,
+	}
+	return k8s.CreateOrUpdateResource(ctx, secret, ctrl.Client)
+}
+
+func (ctrl *Controller) deleteVectorAggregatorEnv(ctx context.Context) error {
+	nn := types.NamespacedName{
+		Name:      ctrl.getNameVectorAggregatorEnv(),
+		Namespace: ctrl.Vector.Namespace,
+	}
+	secret, err := k8s.GetSecret(ctx, nn, ctrl.Client)
+	if err != nil {
+		if api_errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return k8s.DeleteSecret(ctx, secret, ctrl.Client)
+}
+
+func (ctrl *Controller) generateVectorAggregatorEnvFrom() []corev1.EnvFromSource {
+	if ctrl.valueRefsHash == "" {
+		return nil
+	}
+	return valueref.EnvFrom(ctrl.getNameVectorAggregatorEnv())
+}
+
+func (ctrl *Controller) getNameVectorAggregatorEnv() string {
+	return ctrl.Vector.Name + "-aggregator-env"
+}
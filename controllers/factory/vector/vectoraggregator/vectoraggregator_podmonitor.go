@@ -0,0 +1,44 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectoraggregator
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+)
+
+func (ctrl *Controller) createVectorAggregatorPodMonitor() *monitorv1.PodMonitor {
+	labels := ctrl.labelsForVectorAggregator()
+
+	podmonitor := &monitorv1.PodMonitor{
+		ObjectMeta: ctrl.objectMetaVectorAggregator(labels),
+		Spec: monitorv1.PodMonitorSpec{
+			PodMetricsEndpoints: []monitorv1.PodMetricsEndpoint{
+				{
+					Path: "/metrics",
+					Port: "prom-exporter",
+				},
+			},
+			Selector: metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+		},
+	}
+
+	return podmonitor
+}
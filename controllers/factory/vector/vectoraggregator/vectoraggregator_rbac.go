@@ -0,0 +1,31 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectoraggregator
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+func (ctrl *Controller) createVectorAggregatorServiceAccount() *corev1.ServiceAccount {
+	labels := ctrl.labelsForVectorAggregator()
+
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: ctrl.objectMetaVectorAggregator(labels),
+	}
+
+	return serviceAccount
+}
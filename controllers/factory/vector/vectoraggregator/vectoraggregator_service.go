@@ -0,0 +1,67 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectoraggregator
+
+import (
+	"fmt"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	ApiPort    = 8686
+	SourcePort = 6000
+)
+
+func (ctrl *Controller) createVectorAggregatorService() *corev1.Service {
+	labels := ctrl.labelsForVectorAggregator()
+
+	ports := []corev1.ServicePort{
+		{
+			Name:       "vector",
+			Protocol:   corev1.Protocol("TCP"),
+			Port:       SourcePort,
+			TargetPort: intstr.FromInt(SourcePort),
+		},
+	}
+	if ctrl.Vector.Spec.Aggregator.Api.Enabled {
+		ports = append(ports, corev1.ServicePort{
+			Name:       "api",
+			Protocol:   corev1.Protocol("TCP"),
+			Port:       ApiPort,
+			TargetPort: intstr.FromInt(ApiPort),
+		})
+	}
+
+	return &corev1.Service{
+		ObjectMeta: ctrl.objectMetaVectorAggregator(labels),
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports:     ports,
+			Selector:  labels,
+		},
+	}
+}
+
+// ServiceAddress returns address of the Vector Aggregator headless service,
+// which agents use to forward events with vector sink
+func ServiceAddress(v *vectorv1alpha1.Vector) string {
+	return fmt.Sprintf("%s-aggregator.%s.svc:%d", v.Name, v.Namespace, SourcePort)
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectoraggregator
+
+import (
+	"github.com/kaasops/vector-operator/controllers/factory/valueref"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const dataVolumeName = "data"
+
+func (ctrl *Controller) createVectorAggregatorStatefulSet() *appsv1.StatefulSet {
+	labels := ctrl.labelsForVectorAggregator()
+
+	statefulset := &appsv1.StatefulSet{
+		ObjectMeta: ctrl.objectMetaVectorAggregator(labels),
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:            ctrl.Vector.Spec.Aggregator.Replicas,
+			ServiceName:         ctrl.getNameVectorAggregator(),
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			Selector:            &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: ctrl.objectMetaVectorAggregator(labels),
+				Spec: corev1.PodSpec{
+					ServiceAccountName: ctrl.getNameVectorAggregator(),
+					Volumes:            ctrl.generateVectorAggregatorVolume(),
+					SecurityContext:    ctrl.Vector.Spec.Aggregator.SecurityContext,
+					ImagePullSecrets:   ctrl.Vector.Spec.Aggregator.ImagePullSecrets,
+					Affinity:           ctrl.Vector.Spec.Aggregator.Affinity,
+					Tolerations:        ctrl.Vector.Spec.Aggregator.Tolerations,
+					PriorityClassName:  ctrl.Vector.Spec.Aggregator.PriorityClassName,
+					Containers:         []corev1.Container{*ctrl.VectorAggregatorContainer()},
+				},
+			},
+		},
+	}
+
+	if ctrl.Vector.Spec.Aggregator.DataVolumeClaimTemplate != nil {
+		statefulset.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: dataVolumeName, Labels: labels},
+				Spec:       *ctrl.Vector.Spec.Aggregator.DataVolumeClaimTemplate,
+			},
+		}
+	}
+
+	if ctrl.valueRefsHash != "" {
+		statefulset.Spec.Template.Annotations = map[string]string{
+			valueref.HashAnnotation: ctrl.valueRefsHash,
+		}
+	}
+
+	return statefulset
+}
+
+func (ctrl *Controller) generateVectorAggregatorVolume() []corev1.Volume {
+	volume := ctrl.Vector.Spec.Aggregator.Volumes
+
+	volume = append(volume, []corev1.Volume{
+		{
+			Name: "config",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: ctrl.getNameVectorAggregator(),
+				},
+			},
+		},
+	}...)
+
+	// Data dir is mounted from PersistentVolumeClaim of volumeClaimTemplates, if it is set
+	if ctrl.Vector.Spec.Aggregator.DataVolumeClaimTemplate == nil {
+		volume = append(volume, corev1.Volume{
+			Name: dataVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+	}
+
+	return volume
+}
+
+// volumeClaimTemplatesChanged returns true, if StatefulSet must be recreated with desired volumeClaimTemplates
+func volumeClaimTemplatesChanged(desired, existing []corev1.PersistentVolumeClaim) bool {
+	return len(desired) != len(existing) || !equality.Semantic.DeepDerivative(desired, existing)
+}
+
+func (ctrl *Controller) generateVectorAggregatorVolumeMounts() []corev1.VolumeMount {
+	volumeMount := ctrl.Vector.Spec.Aggregator.VolumeMounts
+
+	volumeMount = append(volumeMount, []corev1.VolumeMount{
+		{
+			Name:      "config",
+			MountPath: "/etc/vector",
+		},
+		{
+			Name:      dataVolumeName,
+			MountPath: "/vector-data-dir",
+		},
+	}...)
+
+	return volumeMount
+}
+
+func (ctrl *Controller) generateVectorAggregatorEnvs() []corev1.EnvVar {
+	envs := ctrl.Vector.Spec.Aggregator.Env
+
+	envs = append(envs, []corev1.EnvVar{
+		{
+			Name: "VECTOR_SELF_NODE_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					APIVersion: "v1",
+					FieldPath:  "spec.nodeName",
+				},
+			},
+		},
+		{
+			Name: "VECTOR_SELF_POD_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					APIVersion: "v1",
+					FieldPath:  "metadata.name",
+				},
+			},
+		},
+		{
+			Name: "VECTOR_SELF_POD_NAMESPACE",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					APIVersion: "v1",
+					FieldPath:  "metadata.namespace",
+				},
+			},
+		},
+	}...)
+
+	return envs
+}
+
+func (ctrl *Controller) VectorAggregatorContainer() *corev1.Container {
+	return &corev1.Container{
+		Name:    ctrl.getNameVectorAggregator(),
+		Image:   ctrl.Vector.Spec.Aggregator.Image,
+		Args:    []string{"--config-dir", "/etc/vector", "--watch-config"},
+		Env:     ctrl.generateVectorAggregatorEnvs(),
+		EnvFrom: ctrl.generateVectorAggregatorEnvFrom(),
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          "vector",
+				ContainerPort: SourcePort,
+				Protocol:      "TCP",
+			},
+			{
+				Name:          "prom-exporter",
+				ContainerPort: 9598,
+				Protocol:      "TCP",
+			},
+		},
+		VolumeMounts:    ctrl.generateVectorAggregatorVolumeMounts(),
+		Resources:       ctrl.Vector.Spec.Aggregator.Resources,
+		SecurityContext: ctrl.Vector.Spec.Aggregator.ContainerSecurityContext,
+		ImagePullPolicy: ctrl.Vector.Spec.Aggregator.ImagePullPolicy,
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectoraggregator_test
+
+import (
+	"context"
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoraggregator"
+	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDeleteVectorAggregator(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, vectorv1alpha1.AddToScheme(scheme))
+	require.NoError(t, monitorv1.AddToScheme(scheme))
+
+	hash := uint32(42)
+	v := &vectorv1alpha1.Vector{
+		TypeMeta:   metav1.TypeMeta{APIVersion: vectorv1alpha1.GroupVersion.String(), Kind: "Vector"},
+		ObjectMeta: metav1.ObjectMeta{Name: "vector", Namespace: "test", UID: "uid"},
+		Status:     vectorv1alpha1.VectorStatus{LastAppliedAggregatorConfigHash: &hash},
+	}
+	owned := metav1.ObjectMeta{
+		Name:      "vector-aggregator",
+		Namespace: "test",
+		OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: v.APIVersion, Kind: v.Kind, Name: v.Name, UID: v.UID, Controller: pointer.BoolPtr(true)},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		v,
+		&appsv1.StatefulSet{ObjectMeta: owned},
+		&corev1.Secret{ObjectMeta: owned},
+		&corev1.ServiceAccount{ObjectMeta: owned},
+		&monitorv1.PodMonitor{ObjectMeta: owned},
+		// Service is not created by operator
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "vector-aggregator", Namespace: "test"}},
+	).Build()
+	recorder := record.NewFakeRecorder(10)
+	vagCtrl := vectoraggregator.NewController(v, c, nil)
+	vagCtrl.Recorder = recorder
+
+	require.NoError(t, vagCtrl.DeleteVectorAggregator(ctx))
+	require.Equal(t, "Normal AggregatorDeleted Vector Aggregator vector-aggregator is disabled, resources are deleted", <-recorder.Events)
+	require.Nil(t, v.Status.LastAppliedAggregatorConfigHash)
+
+	key := client.ObjectKey{Name: "vector-aggregator", Namespace: "test"}
+	for _, obj := range []client.Object{&appsv1.StatefulSet{}, &corev1.Secret{}, &corev1.ServiceAccount{}, &monitorv1.PodMonitor{}} {
+		require.True(t, api_errors.IsNotFound(c.Get(ctx, key, obj)))
+	}
+	require.NoError(t, c.Get(ctx, key, &corev1.Service{}))
+
+	// Nothing to delete
+	require.NoError(t, vagCtrl.DeleteVectorAggregator(ctx))
+	require.Empty(t, recorder.Events)
+}
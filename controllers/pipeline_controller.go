@@ -18,12 +18,15 @@ package controllers
 
 import (
 	"context"
-	"sync"
+	"errors"
+	"fmt"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	api_errors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -36,7 +39,9 @@ import (
 	"github.com/kaasops/vector-operator/controllers/factory/config"
 	"github.com/kaasops/vector-operator/controllers/factory/config/configcheck"
 	"github.com/kaasops/vector-operator/controllers/factory/pipeline"
+	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
 	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoragent"
+	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoraggregator"
 )
 
 type PipelineReconciler struct {
@@ -44,10 +49,13 @@ type PipelineReconciler struct {
 	Scheme *runtime.Scheme
 
 	// Temp. Wait this issue - https://github.com/kubernetes-sigs/controller-runtime/issues/452
-	Clientset                  *kubernetes.Clientset
-	PipelineCheckWG            *sync.WaitGroup
-	PipelineDeleteEventTimeout time.Duration
-	ConfigCheckTimeout         time.Duration
+	Clientset          *kubernetes.Clientset
+	PipelineChecks     *pipeline.Batcher
+	ConfigCheckTimeout time.Duration
+	ConfigCheckQueue   *configcheck.Queue
+	Recorder           record.EventRecorder
+	// AllowUnknownComponentTypes disables rejection of component types unknown to operator by structural validation
+	AllowUnknownComponentTypes bool
 }
 
 //+kubebuilder:rbac:groups=observability.kaasops.io,resources=vectorpipelines;clustervectorpipelines,verbs=get;list;watch;create;update;patch;delete
@@ -56,8 +64,6 @@ type PipelineReconciler struct {
 
 var VectorAgentReconciliationSourceChannel = make(chan event.GenericEvent)
 
-const PipelineDeleteEventTimeout time.Duration = 3 * time.Second
-
 func (r *PipelineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := log.FromContext(ctx).WithValues("Pipeline", req.Name)
 
@@ -81,16 +87,11 @@ func (r *PipelineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 
 	if pipelineCR == nil {
 		log.Info("Pipeline CR not found. Ignoring since object must be deleted")
+		// Deleted pipeline labels are unknown, so all Vectors are reconciled after other changes are collected
 		for _, vector := range vectorInstances {
-			r.PipelineCheckWG.Add(1)
-			go func() {
-				VectorAgentReconciliationSourceChannel <- event.GenericEvent{Object: vector}
-				log.Info("Waiting if other deletions will be done during timeout")
-				time.Sleep(r.PipelineDeleteEventTimeout)
-				r.PipelineCheckWG.Done()
-			}()
-			return ctrl.Result{}, nil
+			r.PipelineChecks.Add(ctx, vector, nil)
 		}
+		return ctrl.Result{}, nil
 	}
 
 	// Check Pipeline hash
@@ -103,32 +104,82 @@ func (r *PipelineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, nil
 	}
 
+	// In dry run mode rendered configs are saved to pipeline status instead of config check
+	dryRun := pipelineCR.GetSpec().DryRun
+	var renderedConfigs []vectorv1alpha1.RenderedConfig
+	var failed bool
+	// Aggregator pipeline is invalid only if none of selecting Vectors runs aggregator
+	var withoutAggregator, withAggregator bool
+	pipelineCR.SetRenderedConfigs(nil)
+
 	for _, vector := range vectorInstances {
 		if vector.DeletionTimestamp != nil {
 			continue
 		}
 
-		// Init Controller for Vector Agent
-		vaCtrl := vectoragent.NewController(vector, r.Client, r.Clientset)
+		selected, err := pipeline.IsSelected(ctx, r.Client, pipelineCR, vector)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !selected {
+			log.Info("Pipeline is not selected by Vector, skip config check", "Vector", vector.Name)
+			continue
+		}
 
-		vaCtrl.SetDefault()
-		// Get Vector Config file
-		configBuilder := config.NewBuilder(vaCtrl, pipelineCR)
+		if pipelineCR.GetSpec().GetRole() == vectorv1alpha1.PipelineRoleAggregator {
+			if !vector.IsAggregatorEnabled() {
+				log.Info("Vector Aggregator is not enabled, skip config check", "Vector", vector.Name)
+				withoutAggregator = true
+				continue
+			}
+			withAggregator = true
+		}
 
-		byteConfig, err := configBuilder.GetByteConfig()
+		vaCtrl, vagCtrl, err := r.buildPipelineConfigs(ctx, vector, pipelineCR)
 		if err != nil {
-			if err := pipeline.SetFailedStatus(ctx, r.Client, pipelineCR, err.Error()); err != nil {
+			// Only invalid pipeline is marked failed, other errors (e.g. failed List of templates) are requeued
+			if !config.IsPipelineError(err) {
 				return ctrl.Result{}, err
 			}
-			if err = pipeline.SetLastAppliedPipelineStatus(ctx, r.Client, pipelineCR); err != nil {
+			if err := r.setPipelineFailedStatus(ctx, pipelineCR, config.GetConditionReason(err), err.Error()); err != nil {
 				return ctrl.Result{}, err
 			}
+			// Pipeline error is permanent until pipeline is changed, so it is not requeued
+			log.Error(err, "Invalid pipeline")
+			return ctrl.Result{}, nil
+		}
+
+		if dryRun {
+			rendered := vectorv1alpha1.RenderedConfig{
+				Vector: vector.Namespace + "/" + vector.Name,
+				Agent:  string(vaCtrl.Config),
+			}
+			if vagCtrl != nil {
+				rendered.Aggregator = string(vagCtrl.Config)
+			}
+			renderedConfigs = append(renderedConfigs, rendered)
+			// Vectors keep the last checked spec of pipeline in dry run mode, so they are not reconciled
+			continue
+		}
+
+		// Config check is run for batch of pipelines changed for Vector during PipelineChecks window
+		r.PipelineChecks.Add(ctx, vector, pipelineCR)
+	}
+
+	if withoutAggregator && !withAggregator {
+		if err := r.setPipelineFailedStatus(ctx, pipelineCR, vectorv1alpha1.ReasonAggregatorNotEnabled, config.PipelineAggregatorError.Error()); err != nil {
 			return ctrl.Result{}, err
 		}
+		failed = true
+	}
 
-		vaCtrl.Config = byteConfig
-		r.PipelineCheckWG.Add(1)
-		go r.runPipelineCheck(ctx, pipelineCR, vaCtrl)
+	if dryRun && !failed {
+		if err := pipeline.SetDryRunStatus(ctx, r.Client, pipelineCR, renderedConfigs); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := pipeline.SetLastAppliedPipelineStatus(ctx, r.Client, pipelineCR); err != nil {
+			return ctrl.Result{}, err
+		}
 	}
 
 	log.Info("finish Reconcile Pipeline")
@@ -161,6 +212,10 @@ func (r *PipelineReconciler) findPipelineCustomResourceInstance(ctx context.Cont
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *PipelineReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.PipelineChecks.Check = r.checkPipelines
+	r.PipelineChecks.Done = func(v *vectorv1alpha1.Vector) {
+		VectorAgentReconciliationSourceChannel <- event.GenericEvent{Object: v}
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&vectorv1alpha1.VectorPipeline{}).
 		Watches(&source.Kind{Type: &vectorv1alpha1.ClusterVectorPipeline{}}, &handler.EnqueueRequestForObject{}).
@@ -168,48 +223,199 @@ func (r *PipelineReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
-func (r *PipelineReconciler) runPipelineCheck(ctx context.Context, p pipeline.Pipeline, vaCtrl *vectoragent.Controller) {
-	log := log.FromContext(ctx).WithValues("Pipeline", p.GetName())
+func (r *PipelineReconciler) setPipelineFailedStatus(ctx context.Context, p pipeline.Pipeline, conditionReason, reason string, configErrors ...vectorv1alpha1.ConfigError) error {
+	k8s.Event(r.Recorder, p, corev1.EventTypeWarning, k8s.EventReasonConfigCheckFailed, fmt.Sprintf("%s: %s", conditionReason, reason))
+	if err := pipeline.SetFailedStatus(ctx, r.Client, p, conditionReason, reason, configErrors...); err != nil {
+		return err
+	}
+	return pipeline.SetLastAppliedPipelineStatus(ctx, r.Client, p)
+}
+
+// setPipelineUnknownStatus records config check, that didn't produce result. If configcheck pod can't run,
+// pipeline keeps last known validity
+func (r *PipelineReconciler) setPipelineUnknownStatus(ctx context.Context, p pipeline.Pipeline, checkErr error) error {
+	if errors.Is(checkErr, configcheck.ConfigcheckInfrastructureError) {
+		return pipeline.SetInfrastructureFailureStatus(ctx, r.Client, p, checkErr.Error())
+	}
+	return pipeline.SetUnknownStatus(ctx, r.Client, p, configcheck.GetConditionReason(checkErr), checkErr.Error())
+}
+
+// buildPipelineConfigs returns Vector Agent and Vector Aggregator controllers with configs built from pipelines.
+// Vector Aggregator config is built, if there are aggregator pipelines
+func (r *PipelineReconciler) buildPipelineConfigs(ctx context.Context, v *vectorv1alpha1.Vector, pipelines ...pipeline.Pipeline) (*vectoragent.Controller, *vectoraggregator.Controller, error) {
+	templates, err := config.GetTemplates(ctx, r.Client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Init Controller for Vector Agent
+	vaCtrl := vectoragent.NewController(v, r.Client, r.Clientset)
+
+	vaCtrl.SetDefault()
+	// Get Vector Config file
+	configBuilder := config.NewBuilder(vaCtrl, pipelines...).WithTemplates(templates)
+	byteConfig, err := configBuilder.GetByteConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	vaCtrl.Config = byteConfig
+	vaCtrl.ValueRefs = configBuilder.GetValueRefs()
+
+	var aggregatorPipelines []pipeline.Pipeline
+	for _, p := range pipelines {
+		if p.GetSpec().GetRole() == vectorv1alpha1.PipelineRoleAggregator {
+			aggregatorPipelines = append(aggregatorPipelines, p)
+		}
+	}
+	if len(aggregatorPipelines) == 0 {
+		return vaCtrl, nil, nil
+	}
+
+	// Init Controller for Vector Aggregator
+	vagCtrl := vectoraggregator.NewController(v, r.Client, r.Clientset)
+
+	vagCtrl.SetDefault()
+
+	aggregatorBuilder := config.NewAggregatorBuilder(vagCtrl, aggregatorPipelines...).WithTemplates(templates)
+	aggregatorConfig, err := aggregatorBuilder.GetByteConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	vagCtrl.Config = aggregatorConfig
+	vagCtrl.ValueRefs = aggregatorBuilder.GetValueRefs()
+	return vaCtrl, vagCtrl, nil
+}
+
+// checkPipelines runs one config check for pipelines changed for Vector. If combined config fails, pipelines are
+// checked individually, so only invalid pipelines are marked invalid
+func (r *PipelineReconciler) checkPipelines(ctx context.Context, v *vectorv1alpha1.Vector, pipelines []pipeline.Pipeline) {
+	log := log.FromContext(ctx).WithValues("Vector", v.Name)
+	if len(pipelines) == 0 {
+		return
+	}
+	if len(pipelines) > 1 {
+		log.Info("Check pipelines together", "count", len(pipelines))
+		vaCtrl, vagCtrl, err := r.buildPipelineConfigs(ctx, v, pipelines...)
+		if err == nil {
+			_, err = r.validatePipelineConfigs(ctx, vaCtrl, vagCtrl, pipelines...)
+		}
+		switch {
+		case err == nil:
+			for _, p := range pipelines {
+				r.setPipelineSuccessStatus(ctx, p)
+			}
+			return
+		case config.IsPipelineError(err), errors.Is(err, configcheck.ValidationError):
+			log.Info("Combined config check failed, check pipelines individually", "reason", err.Error())
+		default:
+			log.Error(err, "Configcheck error")
+			if configcheck.IsResultUnknown(err) {
+				for _, p := range pipelines {
+					if err := r.setPipelineUnknownStatus(ctx, p, err); err != nil {
+						log.Error(err, "Failed to set pipeline status", "Pipeline", p.GetName())
+					}
+				}
+			}
+			return
+		}
+	}
+
+	for _, p := range pipelines {
+		vaCtrl, vagCtrl, err := r.buildPipelineConfigs(ctx, v, p)
+		if err != nil {
+			if !config.IsPipelineError(err) {
+				log.Error(err, "Failed to build pipeline config", "Pipeline", p.GetName())
+				continue
+			}
+			if err := r.setPipelineFailedStatus(ctx, p, config.GetConditionReason(err), err.Error()); err != nil {
+				log.Error(err, "Failed to set pipeline status", "Pipeline", p.GetName())
+			}
+			continue
+		}
+		r.runPipelineCheck(ctx, p, vaCtrl, vagCtrl)
+	}
+}
+
+// validatePipelineConfigs runs config check of Vector Agent and Vector Aggregator configs with pipelines.
+// Config check events are emitted on every checked pipeline
+func (r *PipelineReconciler) validatePipelineConfigs(ctx context.Context, vaCtrl *vectoragent.Controller, vagCtrl *vectoraggregator.Controller, pipelines ...pipeline.Pipeline) (string, error) {
+	eventObjects := make([]runtime.Object, 0, len(pipelines))
+	for _, p := range pipelines {
+		eventObjects = append(eventObjects, p)
+	}
+
 	// Init CheckConfig
-	configCheck := configcheck.New(
+	agentConfigCheck := configcheck.New(
 		vaCtrl.Config,
 		vaCtrl.Client,
 		vaCtrl.ClientSet,
 		vaCtrl.Vector,
 		r.ConfigCheckTimeout,
 	)
-	configCheck.Initiator = configcheck.ConfigCheckInitiatorPipieline
-	defer r.PipelineCheckWG.Done()
-	// Start ConfigCheck
-	reason, err := configCheck.Run(ctx)
-	if reason != "" {
-		if err = pipeline.SetFailedStatus(ctx, r.Client, p, reason); err != nil {
-			log.Error(err, "Failed to set pipeline status")
-			return
+	agentConfigCheck.ValueRefs = vaCtrl.ValueRefs
+	agentConfigCheck.Recorder = r.Recorder
+	agentConfigCheck.EventObjects = eventObjects
+	agentConfigCheck.ComponentResolver = config.NewComponentResolver(pipelines...)
+	agentConfigCheck.Queue = r.ConfigCheckQueue
+	configChecks := []*configcheck.ConfigCheck{agentConfigCheck}
+	if vagCtrl != nil {
+		aggregatorConfigCheck := configcheck.NewAggregator(
+			vagCtrl.Config,
+			vagCtrl.Client,
+			vagCtrl.ClientSet,
+			vagCtrl.Vector,
+			r.ConfigCheckTimeout,
+		)
+		aggregatorConfigCheck.ValueRefs = vagCtrl.ValueRefs
+		aggregatorConfigCheck.Recorder = r.Recorder
+		aggregatorConfigCheck.EventObjects = eventObjects
+		aggregatorConfigCheck.ComponentResolver = config.NewComponentResolver(pipelines...)
+		aggregatorConfigCheck.Queue = r.ConfigCheckQueue
+		configChecks = append(configChecks, aggregatorConfigCheck)
+	}
+
+	for _, configCheck := range configChecks {
+		configCheck.Initiator = configcheck.ConfigCheckInitiatorPipieline
+		// Start ConfigCheck
+		if reason, err := configCheck.WithStructural(r.AllowUnknownComponentTypes).Validate(ctx, configCheck.Config); err != nil {
+			return reason, err
 		}
-		if err = pipeline.SetLastAppliedPipelineStatus(ctx, r.Client, p); err != nil {
+	}
+	return "", nil
+}
+
+func (r *PipelineReconciler) runPipelineCheck(ctx context.Context, p pipeline.Pipeline, vaCtrl *vectoragent.Controller, vagCtrl *vectoraggregator.Controller) {
+	log := log.FromContext(ctx).WithValues("Pipeline", p.GetName())
+
+	reason, err := r.validatePipelineConfigs(ctx, vaCtrl, vagCtrl, p)
+	if reason != "" {
+		if err = r.setPipelineFailedStatus(ctx, p, configcheck.GetConditionReason(err), reason, configcheck.GetConfigErrors(err)...); err != nil {
 			log.Error(err, "Failed to set pipeline status")
-			return
 		}
 		return
 	}
 
 	if err != nil {
 		log.Error(err, "Configcheck error")
+		if configcheck.IsResultUnknown(err) {
+			if err := r.setPipelineUnknownStatus(ctx, p, err); err != nil {
+				log.Error(err, "Failed to set pipeline status")
+			}
+		}
 		return
 	}
 
-	if err = pipeline.SetSuccessStatus(ctx, r.Client, p); err != nil {
+	r.setPipelineSuccessStatus(ctx, p)
+}
+
+func (r *PipelineReconciler) setPipelineSuccessStatus(ctx context.Context, p pipeline.Pipeline) {
+	log := log.FromContext(ctx).WithValues("Pipeline", p.GetName())
+	if err := pipeline.SetSuccessStatus(ctx, r.Client, p); err != nil {
 		log.Error(err, "Failed to set pipeline status")
 		return
 	}
 
-	if err = pipeline.SetLastAppliedPipelineStatus(ctx, r.Client, p); err != nil {
+	if err := pipeline.SetLastAppliedPipelineStatus(ctx, r.Client, p); err != nil {
 		log.Error(err, "Failed to set pipeline status")
-		return
-	}
-	// Start vector reconcilation
-	if *p.GetConfigCheckResult() {
-		VectorAgentReconciliationSourceChannel <- event.GenericEvent{Object: vaCtrl.Vector}
 	}
 }
@@ -0,0 +1,106 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/config"
+	"github.com/kaasops/vector-operator/controllers/factory/pipeline"
+)
+
+// TransformTemplateReconciler checks pipelines, that reference changed or deleted VectorTransformTemplate.
+// Pipeline spec is not changed with template, so pipelines are checked with PipelineChecks batches
+// and Vectors are reconciled after checks
+type TransformTemplateReconciler struct {
+	client.Client
+	PipelineChecks *pipeline.Batcher
+}
+
+//+kubebuilder:rbac:groups=observability.kaasops.io,resources=vectortransformtemplates,verbs=get;list;watch
+
+func (r *TransformTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx).WithValues("VectorTransformTemplate", req.Name)
+
+	vectors, err := listVectorCustomResourceInstances(ctx, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	pipelines, err := r.getReferencingPipelines(ctx, req.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	for _, p := range pipelines {
+		for _, vector := range vectors {
+			if vector.DeletionTimestamp != nil {
+				continue
+			}
+			selected, err := pipeline.IsSelected(ctx, r.Client, p, vector)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if selected {
+				log.Info("Template changed, check pipeline", "Pipeline", pipeline.Ref(p), "Vector", vector.Name)
+				r.PipelineChecks.Add(ctx, vector, p)
+			}
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// getReferencingPipelines returns pipelines with template transforms referencing template. Pipelines in dry run
+// mode are skipped, their configs are rendered on pipeline change
+func (r *TransformTemplateReconciler) getReferencingPipelines(ctx context.Context, name string) ([]pipeline.Pipeline, error) {
+	vps, err := pipeline.GetVectorPipelines(ctx, r.Client)
+	if err != nil {
+		return nil, err
+	}
+	cvps, err := pipeline.GetClusterVectorPipelines(ctx, r.Client)
+	if err != nil {
+		return nil, err
+	}
+	var pipelines []pipeline.Pipeline
+	for i := range vps {
+		pipelines = append(pipelines, &vps[i])
+	}
+	for i := range cvps {
+		pipelines = append(pipelines, &cvps[i])
+	}
+
+	var result []pipeline.Pipeline
+	for _, p := range pipelines {
+		if p.IsDeleted() || p.GetSpec().DryRun || !config.ReferencesTemplate(p, name) {
+			continue
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TransformTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&vectorv1alpha1.VectorTransformTemplate{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r)
+}
@@ -19,15 +19,17 @@ package controllers
 import (
 	"context"
 	"errors"
-	"sync"
+	"fmt"
 	"time"
 
 	"github.com/kaasops/vector-operator/controllers/factory/config"
 	"github.com/kaasops/vector-operator/controllers/factory/config/configcheck"
+	"github.com/kaasops/vector-operator/controllers/factory/metrics"
 	"github.com/kaasops/vector-operator/controllers/factory/pipeline"
 	"github.com/kaasops/vector-operator/controllers/factory/utils/hash"
 	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
 	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoragent"
+	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoraggregator"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -35,32 +37,53 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 
 	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
 	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 )
 
+const AgentNotReadyRequeueInterval time.Duration = 30 * time.Second
+
+// maxBisectChecks limits config checks run to find pipeline, that fails config check of Vector. Every check can
+// take up to ConfigCheckTimeout, so reconcile worker is not held for log2(pipelines) checks
+const maxBisectChecks = 4
+
+var errBisectLimit = errors.New("config checks limit for pipeline bisection is reached")
+
+// maxExclusionRounds limits rounds of config build and check in one reconcile of Vector. Every round excludes failed
+// pipelines and can run config checks, so Vector with many failing pipelines is requeued instead of holding worker
+const maxExclusionRounds = 3
+
 // VectorReconciler reconciles a Vector object
 type VectorReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 
 	// Temp. Wait this issue - https://github.com/kubernetes-sigs/controller-runtime/issues/452
-	Clientset            *kubernetes.Clientset
-	PipelineCheckWG      *sync.WaitGroup
-	PipelineCheckTimeout time.Duration
-	ConfigCheckTimeout   time.Duration
-	DiscoveryClient      *discovery.DiscoveryClient
+	Clientset          *kubernetes.Clientset
+	PipelineChecks     *pipeline.Batcher
+	ConfigCheckTimeout time.Duration
+	ConfigCheckQueue   *configcheck.Queue
+	DiscoveryClient    *discovery.DiscoveryClient
+	Recorder           record.EventRecorder
+	// AllowUnknownComponentTypes disables rejection of component types unknown to operator by structural validation
+	AllowUnknownComponentTypes bool
 }
 
 //+kubebuilder:rbac:groups=observability.kaasops.io,resources=vectors,verbs=get;list;watch;create;update;patch;delete
@@ -68,19 +91,22 @@ type VectorReconciler struct {
 //+kubebuilder:rbac:groups=observability.kaasops.io,resources=vectors/finalizers,verbs=update
 
 // +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups="",resources=sercrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups="",resources=pods/log,verbs=get
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
 // +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=clusterroles,verbs=get;list;watch;create;update;patch;delete
 
 func (r *VectorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	_ = log.FromContext(ctx)
 	log := log.FromContext(ctx).WithValues("Vector", req.NamespacedName)
-	log.Info("Waiting pipeline checks")
-	if waitPipelineChecks(r.PipelineCheckWG, r.PipelineCheckTimeout) {
-		log.Info("Timeout waiting pipeline checks, continue reconcile vector")
-	}
 	log.Info("Start Reconcile Vector")
 	if req.Namespace == "" {
 		vectors, err := listVectorCustomResourceInstances(ctx, r.Client)
@@ -101,6 +127,16 @@ func (r *VectorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, nil
 	}
 
+	if vectorCR.DeletionTimestamp != nil {
+		return ctrl.Result{}, r.finalizeVector(ctx, vectorCR)
+	}
+	if !controllerutil.ContainsFinalizer(vectorCR, vectorv1alpha1.VectorFinalizer) {
+		controllerutil.AddFinalizer(vectorCR, vectorv1alpha1.VectorFinalizer)
+		if err := r.Update(ctx, vectorCR); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	return r.createOrUpdateVector(ctx, r.Client, r.Clientset, vectorCR, false)
 }
 
@@ -114,11 +150,13 @@ func (r *VectorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&vectorv1alpha1.Vector{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Watches(&source.Channel{Source: VectorAgentReconciliationSourceChannel}, &handler.EnqueueRequestForObject{}).
 		Owns(&appsv1.DaemonSet{}).
+		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.Secret{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&rbacv1.ClusterRole{}).
-		Owns(&rbacv1.ClusterRoleBinding{})
+		Owns(&rbacv1.ClusterRoleBinding{}).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(r.namespaceToVectors), builder.WithPredicates(predicate.LabelChangedPredicate{}))
 
 	if monitoringCRD {
 		builder.Owns(&monitorv1.PodMonitor{})
@@ -130,14 +168,32 @@ func (r *VectorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return nil
 }
 
+// namespaceToVectors returns Vectors, that select pipelines by namespace labels, so pipelines are added
+// and removed when namespace labels are changed
+func (r *VectorReconciler) namespaceToVectors(obj client.Object) []reconcile.Request {
+	vectors, err := listVectorCustomResourceInstances(context.Background(), r.Client)
+	if err != nil {
+		log.Log.Error(err, "Failed to list vector instances", "Namespace", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, v := range vectors {
+		if v.Spec.PipelineNamespaceSelector == nil {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: v.Namespace, Name: v.Name}})
+	}
+	return requests
+}
+
 func listVectorCustomResourceInstances(ctx context.Context, client client.Client) (vectors []*vectorv1alpha1.Vector, err error) {
 	vectorlist := vectorv1alpha1.VectorList{}
 	err = client.List(ctx, &vectorlist)
 	if err != nil {
 		return nil, err
 	}
-	for _, vector := range vectorlist.Items {
-		vectors = append(vectors, &vector)
+	for i := range vectorlist.Items {
+		vectors = append(vectors, &vectorlist.Items[i])
 	}
 	return vectors, nil
 }
@@ -160,67 +216,158 @@ func (r *VectorReconciler) reconcileVectors(ctx context.Context, client client.C
 		return ctrl.Result{}, nil
 	}
 
+	var result ctrl.Result
 	for _, vector := range vectors {
 		if vector.DeletionTimestamp != nil {
 			continue
 		}
-		if _, err := r.createOrUpdateVector(ctx, client, clientset, vector, configOnly); err != nil {
+		res, err := r.createOrUpdateVector(ctx, client, clientset, vector, configOnly)
+		if err != nil {
 			return ctrl.Result{}, err
 		}
+		if res.Requeue {
+			result.Requeue = true
+		}
 	}
-	return ctrl.Result{}, nil
+	return result, nil
 }
 
 func (r *VectorReconciler) createOrUpdateVector(ctx context.Context, client client.Client, clientset *kubernetes.Clientset, v *vectorv1alpha1.Vector, configOnly bool) (ctrl.Result, error) {
 	_ = log.FromContext(ctx)
 	log := log.FromContext(ctx).WithValues("Vector", v.Name)
+	// Changed pipelines are not applied before they are checked, Vector is reconciled after batch check
+	if r.PipelineChecks.Pending(types.NamespacedName{Namespace: v.Namespace, Name: v.Name}) {
+		log.Info("Pipeline checks are pending, Vector is reconciled after them")
+		return ctrl.Result{}, nil
+	}
 	// Init Controller for Vector Agent
 	vaCtrl := vectoragent.NewController(v, client, clientset)
+	vaCtrl.Recorder = r.Recorder
 
 	vaCtrl.SetDefault()
 
 	// Get Vector Config file
-	pipelines, err := pipeline.GetValidPipelines(ctx, vaCtrl.Client)
+	pipelines, err := pipeline.GetValidPipelines(ctx, vaCtrl.Client, v)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-	configBuilder := config.NewBuilder(vaCtrl, pipelines...)
-
-	// Get Config in Json ([]byte)
-	byteConfig, err := configBuilder.GetByteConfig()
+	// Pipelines, that failed config of this Vector, are not tried again until they are changed
+	pipelines, exclusions, err := pipeline.ApplyExclusions(pipelines, v.Status.ExcludedPipelines)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-	cfgHash := hash.Get(byteConfig)
+	v.Status.ExcludedPipelines = exclusions
 
-	if vaCtrl.Vector.Status.LastAppliedConfigHash == nil || *vaCtrl.Vector.Status.LastAppliedConfigHash != cfgHash {
-		configCheck := configcheck.New(
-			byteConfig,
-			vaCtrl.Client,
-			vaCtrl.ClientSet,
-			vaCtrl.Vector,
-			r.ConfigCheckTimeout,
-		)
-		configCheck.Initiator = configcheck.ConfigCheckInitiatorVector
-		reason, err := configCheck.Run(ctx)
+	var vagCtrl *vectoraggregator.Controller
+	if v.IsAggregatorEnabled() {
+		// Init Controller for Vector Aggregator
+		vagCtrl = vectoraggregator.NewController(v, client, clientset)
+		vagCtrl.Recorder = r.Recorder
+
+		vagCtrl.SetDefault()
+	}
+
+	// Pipelines, that fail config build or config check of Vector, are excluded from its configs, until config passes check
+	for round := 0; ; round++ {
+		if round == maxExclusionRounds {
+			log.Info("Config still fails after exclusion of pipelines, Vector is reconciled again", "rounds", maxExclusionRounds)
+			return ctrl.Result{Requeue: true}, nil
+		}
+		configBuilder, aggregatorBuilder, err := buildConfigs(ctx, vaCtrl, vagCtrl, pipelines)
 		if err != nil {
-			if errors.Is(err, configcheck.ValidationError) {
-				if err := vaCtrl.SetFailedStatus(ctx, reason); err != nil {
+			if !config.IsPipelineError(err) {
+				return ctrl.Result{}, err
+			}
+			invalid, pipelineErrors := findInvalidPipelines(pipelines, err)
+			if len(invalid) == 0 {
+				if err := vaCtrl.SetFailedStatus(ctx, config.GetConditionReason(err), err.Error()); err != nil {
 					return ctrl.Result{}, err
 				}
-				log.Error(err, "Invalid config")
+				log.Error(err, "Invalid pipelines in config")
 				return ctrl.Result{}, nil
 			}
-			return ctrl.Result{}, err
+			if err := r.excludeInvalidPipelines(ctx, vaCtrl, invalid, pipelineErrors); err != nil {
+				return ctrl.Result{}, err
+			}
+			pipelines = pipeline.Exclude(pipelines, invalid)
+			continue
+		}
+		recordConfigMetrics(v, metrics.RoleAgent, vaCtrl.Config, configBuilder)
+		if aggregatorBuilder != nil {
+			recordConfigMetrics(v, metrics.RoleAggregator, vagCtrl.Config, aggregatorBuilder)
 		}
 
+		if v.Spec.DryRun {
+			return ctrl.Result{}, r.renderVectorConfig(ctx, vaCtrl, vagCtrl)
+		}
+
+		reason, err := r.checkConfigs(ctx, vaCtrl, vagCtrl, pipelines)
+		if err == nil {
+			break
+		}
+		if errors.Is(err, configcheck.ValidationError) {
+			failed, findErr := r.findFailedPipelines(ctx, vaCtrl, vagCtrl, pipelines, err)
+			if findErr != nil {
+				return ctrl.Result{}, findErr
+			}
+			if len(failed) == 0 {
+				if err := vaCtrl.SetFailedStatus(ctx, configcheck.GetConditionReason(err), reason, configcheck.GetConfigErrors(err)...); err != nil {
+					return ctrl.Result{}, err
+				}
+				log.Error(err, "Invalid config")
+				return ctrl.Result{}, nil
+			}
+			if err := r.excludePipelines(ctx, vaCtrl, failed, reason, err); err != nil {
+				return ctrl.Result{}, err
+			}
+			pipelines = pipeline.Exclude(pipelines, failed)
+			continue
+		}
+		switch {
+		case errors.Is(err, configcheck.ConfigcheckInfrastructureError):
+			if err := vaCtrl.SetInfrastructureFailureStatus(ctx, err.Error()); err != nil {
+				return ctrl.Result{}, err
+			}
+		case errors.Is(err, configcheck.ConfigcheckTimeoutError):
+			if err := vaCtrl.SetUnknownStatus(ctx, configcheck.GetConditionReason(err), err.Error()); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, err
+	}
+	cfgHash := hash.Get(vaCtrl.Config)
+	var aggregatorCfgHash uint32
+	if vagCtrl != nil {
+		aggregatorCfgHash = hash.Get(vagCtrl.Config)
 	}
-	vaCtrl.Config = byteConfig
 
 	// Start Reconcile Vector Agent
 	if err := vaCtrl.EnsureVectorAgent(ctx, configOnly); err != nil {
 		return ctrl.Result{}, err
 	}
+	if err := vaCtrl.SetAgentStatus(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := vaCtrl.DeleteRenderedConfig(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if vagCtrl != nil {
+		// Start Reconcile Vector Aggregator
+		if err := vagCtrl.EnsureVectorAggregator(ctx, configOnly); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := vagCtrl.SetLastAppliedConfigStatus(ctx, &aggregatorCfgHash); err != nil {
+			return ctrl.Result{}, err
+		}
+	} else {
+		// Aggregator could be disabled after it was deployed
+		vagCtrl = vectoraggregator.NewController(v, client, clientset)
+		vagCtrl.Recorder = r.Recorder
+		if err := vagCtrl.DeleteVectorAggregator(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
 
 	if err := vaCtrl.SetLastAppliedPipelineStatus(ctx, &cfgHash); err != nil {
 		//TODO: Handle err: Operation cannot be fulfilled on vectors.observability.kaasops.io \"vector-sample\": the object has been modified; please apply your changes to the latest version and try again
@@ -237,20 +384,272 @@ func (r *VectorReconciler) createOrUpdateVector(ctx context.Context, client clie
 		}
 		return ctrl.Result{}, err
 	}
+	metrics.SetLastSuccessfulApply(v.Namespace, v.Name, time.Now())
+
+	// Crash-looping pods don't always change DaemonSet status, so check agent again later
+	if !meta.IsStatusConditionTrue(v.Status.Conditions, vectorv1alpha1.ConditionAgentReady) {
+		return ctrl.Result{RequeueAfter: AgentNotReadyRequeueInterval}, nil
+	}
 
 	return ctrl.Result{}, nil
 }
 
-func waitPipelineChecks(wg *sync.WaitGroup, timeout time.Duration) bool {
-	c := make(chan struct{})
-	go func() {
-		defer close(c)
-		wg.Wait()
-	}()
-	select {
-	case <-c:
-		return false
-	case <-time.After(timeout):
-		return true
+// buildConfigs builds Vector Agent and Vector Aggregator configs from pipelines and saves them to controllers
+func buildConfigs(ctx context.Context, vaCtrl *vectoragent.Controller, vagCtrl *vectoraggregator.Controller, pipelines []pipeline.Pipeline) (*config.Builder, *config.Builder, error) {
+	templates, err := config.GetTemplates(ctx, vaCtrl.Client)
+	if err != nil {
+		return nil, nil, err
+	}
+	configBuilder := config.NewBuilder(vaCtrl, pipelines...).WithTemplates(templates)
+	byteConfig, err := configBuilder.GetByteConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	vaCtrl.Config = byteConfig
+	vaCtrl.ValueRefs = configBuilder.GetValueRefs()
+
+	if vagCtrl == nil {
+		return configBuilder, nil, nil
+	}
+	aggregatorBuilder := config.NewAggregatorBuilder(vagCtrl, pipelines...).WithTemplates(templates)
+	aggregatorConfig, err := aggregatorBuilder.GetByteConfig()
+	if err != nil {
+		return nil, nil, err
 	}
+	vagCtrl.Config = aggregatorConfig
+	vagCtrl.ValueRefs = aggregatorBuilder.GetValueRefs()
+	return configBuilder, aggregatorBuilder, nil
+}
+
+// checkConfigs runs config check of built configs, that differ from the last applied ones.
+// If config is invalid, reason and ValidationError are returned
+func (r *VectorReconciler) checkConfigs(ctx context.Context, vaCtrl *vectoragent.Controller, vagCtrl *vectoraggregator.Controller, pipelines []pipeline.Pipeline) (string, error) {
+	v := vaCtrl.Vector
+	if v.Status.LastAppliedConfigHash == nil || *v.Status.LastAppliedConfigHash != hash.Get(vaCtrl.Config) {
+		configCheck := configcheck.New(
+			vaCtrl.Config,
+			vaCtrl.Client,
+			vaCtrl.ClientSet,
+			v,
+			r.ConfigCheckTimeout,
+		)
+		configCheck.Initiator = configcheck.ConfigCheckInitiatorVector
+		configCheck.ValueRefs = vaCtrl.ValueRefs
+		configCheck.Recorder = r.Recorder
+		configCheck.EventObjects = []runtime.Object{v}
+		configCheck.ComponentResolver = config.NewComponentResolver(pipelines...)
+		configCheck.Queue = r.ConfigCheckQueue
+		if reason, err := configCheck.WithStructural(r.AllowUnknownComponentTypes).Validate(ctx, vaCtrl.Config); err != nil {
+			return reason, err
+		}
+	}
+
+	if vagCtrl == nil {
+		return "", nil
+	}
+	if v.Status.LastAppliedAggregatorConfigHash == nil || *v.Status.LastAppliedAggregatorConfigHash != hash.Get(vagCtrl.Config) {
+		configCheck := configcheck.NewAggregator(
+			vagCtrl.Config,
+			vagCtrl.Client,
+			vagCtrl.ClientSet,
+			v,
+			r.ConfigCheckTimeout,
+		)
+		configCheck.Initiator = configcheck.ConfigCheckInitiatorVector
+		configCheck.ValueRefs = vagCtrl.ValueRefs
+		configCheck.Recorder = r.Recorder
+		configCheck.EventObjects = []runtime.Object{v}
+		configCheck.ComponentResolver = config.NewComponentResolver(pipelines...)
+		configCheck.Queue = r.ConfigCheckQueue
+		if reason, err := configCheck.WithStructural(r.AllowUnknownComponentTypes).Validate(ctx, vagCtrl.Config); err != nil {
+			return "Vector Aggregator: " + reason, err
+		}
+	}
+	return "", nil
+}
+
+// findFailedPipelines returns pipelines, that fail config check of Vector. Pipelines are found by config errors.
+// If errors can't be attributed to pipelines, failed pipeline is found by bisection, but only when config
+// without pipelines passes check. Nil is returned, if failure is not caused by single pipeline or pipeline is not
+// found within maxBisectChecks config checks
+func (r *VectorReconciler) findFailedPipelines(ctx context.Context, vaCtrl *vectoragent.Controller, vagCtrl *vectoraggregator.Controller, pipelines []pipeline.Pipeline, checkErr error) ([]pipeline.Pipeline, error) {
+	if failed := pipeline.Attribute(pipelines, configcheck.GetConfigErrors(checkErr)); len(failed) != 0 {
+		return failed, nil
+	}
+	if len(pipelines) == 0 {
+		return nil, nil
+	}
+
+	// Config checks run in reconcile worker, so number of checks for bisection is limited
+	checks := 0
+	checkFailed := func(ctx context.Context, pipelines []pipeline.Pipeline) (bool, error) {
+		if checks == maxBisectChecks {
+			return false, errBisectLimit
+		}
+		checks++
+		if _, _, err := buildConfigs(ctx, vaCtrl, vagCtrl, pipelines); err != nil {
+			if config.IsPipelineError(err) {
+				return true, nil
+			}
+			return false, err
+		}
+		if _, err := r.checkConfigs(ctx, vaCtrl, vagCtrl, pipelines); err != nil {
+			if errors.Is(err, configcheck.ValidationError) {
+				return true, nil
+			}
+			return false, err
+		}
+		return false, nil
+	}
+
+	if failed, err := checkFailed(ctx, nil); err != nil || failed {
+		return nil, err
+	}
+	failed, err := pipeline.Bisect(ctx, pipelines, checkFailed)
+	if errors.Is(err, errBisectLimit) {
+		log.FromContext(ctx).Info("Failed pipeline is not found within config checks limit", "Vector", vaCtrl.Vector.Name, "checks", maxBisectChecks)
+		return nil, nil
+	}
+	if err != nil || failed == nil {
+		return nil, err
+	}
+	return []pipeline.Pipeline{failed}, nil
+}
+
+// findInvalidPipelines returns pipelines, that config build error is attributed to, with their errors
+func findInvalidPipelines(pipelines []pipeline.Pipeline, buildErr error) ([]pipeline.Pipeline, map[string]error) {
+	pipelineErrors := config.GetPipelineErrors(buildErr)
+	var invalid []pipeline.Pipeline
+	for _, p := range pipelines {
+		if _, ok := pipelineErrors[pipeline.Ref(p)]; ok {
+			invalid = append(invalid, p)
+		}
+	}
+	return invalid, pipelineErrors
+}
+
+// excludeInvalidPipelines records pipelines, that fail config build of Vector, in Vector status. Pipelines are
+// excluded from configs of this Vector, until they are changed. Status of pipelines and other Vectors is not changed
+func (r *VectorReconciler) excludeInvalidPipelines(ctx context.Context, vaCtrl *vectoragent.Controller, invalid []pipeline.Pipeline, pipelineErrors map[string]error) error {
+	log := log.FromContext(ctx).WithValues("Vector", vaCtrl.Vector.Name)
+	exclusions := vaCtrl.Vector.Status.ExcludedPipelines
+	for _, p := range invalid {
+		pipelineErr := pipelineErrors[pipeline.Ref(p)]
+		k8s.Event(r.Recorder, p, corev1.EventTypeWarning, k8s.EventReasonConfigCheckFailed, fmt.Sprintf("Config build of Vector %s/%s failed with pipeline: %s", vaCtrl.Vector.Namespace, vaCtrl.Vector.Name, pipelineErr))
+		exclusion, err := pipeline.NewExclusion(p, config.GetConditionReason(pipelineErr), pipelineErr.Error())
+		if err != nil {
+			return err
+		}
+		exclusions = append(exclusions, exclusion)
+		k8s.Event(r.Recorder, vaCtrl.Vector, corev1.EventTypeWarning, k8s.EventReasonPipelineExcluded, fmt.Sprintf("Pipeline %s is excluded from config: %s", pipeline.Ref(p), pipelineErr))
+		log.Info("Pipeline is invalid and is excluded from config", "pipeline", pipeline.Ref(p), "reason", pipelineErr.Error())
+	}
+	return vaCtrl.SetExcludedPipelinesStatus(ctx, exclusions)
+}
+
+// excludePipelines records pipelines, that fail config check of Vector, in Vector status. Pipelines are excluded
+// from configs of this Vector, until they are changed. Status of pipelines and other Vectors is not changed
+func (r *VectorReconciler) excludePipelines(ctx context.Context, vaCtrl *vectoragent.Controller, failed []pipeline.Pipeline, reason string, checkErr error) error {
+	log := log.FromContext(ctx).WithValues("Vector", vaCtrl.Vector.Name)
+	configErrors := configcheck.GetConfigErrors(checkErr)
+	exclusions := vaCtrl.Vector.Status.ExcludedPipelines
+	for _, p := range failed {
+		pipelineErrors := pipeline.ConfigErrorsFor(p, configErrors)
+		pipelineReason := reason
+		if len(pipelineErrors) != 0 {
+			pipelineReason = configcheck.FormatConfigErrors(pipelineErrors)
+		}
+		message := fmt.Sprintf("Config check of Vector %s/%s failed with pipeline: %s", vaCtrl.Vector.Namespace, vaCtrl.Vector.Name, pipelineReason)
+		k8s.Event(r.Recorder, p, corev1.EventTypeWarning, k8s.EventReasonConfigCheckFailed, message)
+		exclusion, err := pipeline.NewExclusion(p, vectorv1alpha1.ReasonVectorConfigCheckFailed, pipelineReason)
+		if err != nil {
+			return err
+		}
+		exclusions = append(exclusions, exclusion)
+		k8s.Event(r.Recorder, vaCtrl.Vector, corev1.EventTypeWarning, k8s.EventReasonPipelineExcluded, fmt.Sprintf("Pipeline %s is excluded from config: %s", pipeline.Ref(p), pipelineReason))
+		log.Info("Pipeline failed config check and is excluded from config", "pipeline", pipeline.Ref(p))
+	}
+	return vaCtrl.SetExcludedPipelinesStatus(ctx, exclusions)
+}
+
+// renderVectorConfig validates configs, built by buildConfigs, in operator process and saves them to Secret
+// without config check and deploy
+func (r *VectorReconciler) renderVectorConfig(ctx context.Context, vaCtrl *vectoragent.Controller, vagCtrl *vectoraggregator.Controller) error {
+	log := log.FromContext(ctx).WithValues("Vector", vaCtrl.Vector.Name)
+
+	agentConfig := vaCtrl.Config
+	var aggregatorConfig []byte
+	if vagCtrl != nil {
+		aggregatorConfig = vagCtrl.Config
+	}
+
+	for _, cfg := range [][]byte{agentConfig, aggregatorConfig} {
+		if cfg == nil {
+			continue
+		}
+		reason, err := configcheck.NewStructuralValidator(r.AllowUnknownComponentTypes).Validate(ctx, cfg)
+		if err != nil {
+			if errors.Is(err, configcheck.ValidationError) {
+				log.Error(err, "Invalid config")
+				return vaCtrl.SetFailedStatus(ctx, configcheck.GetConditionReason(err), reason)
+			}
+			return err
+		}
+	}
+
+	if err := vaCtrl.EnsureRenderedConfig(ctx, agentConfig, aggregatorConfig); err != nil {
+		return err
+	}
+	log.Info("Vector config is rendered in dry run mode")
+	return vaCtrl.SetDryRunStatus(ctx)
+}
+
+// finalizeVector removes resources, that are not garbage-collected by owner reference, and releases Vector.
+// Cleanup progress is reported in Terminating condition
+func (r *VectorReconciler) finalizeVector(ctx context.Context, v *vectorv1alpha1.Vector) error {
+	log := log.FromContext(ctx).WithValues("Vector", v.Name)
+	if !controllerutil.ContainsFinalizer(v, vectorv1alpha1.VectorFinalizer) {
+		return nil
+	}
+	log.Info("Start cleanup Vector resources")
+
+	vaCtrl := vectoragent.NewController(v, r.Client, r.Clientset)
+	steps := []struct {
+		message string
+		run     func(ctx context.Context) error
+	}{
+		{"Deleting Vector Agent ClusterRole and ClusterRoleBinding", vaCtrl.DeleteVectorAgentClusterRBAC},
+		{"Deleting config check pods, Secrets and ServiceAccount", func(ctx context.Context) error {
+			return configcheck.Cleanup(ctx, r.Client, r.Clientset, v)
+		}},
+	}
+	for _, step := range steps {
+		v.SetCondition(vectorv1alpha1.ConditionTerminating, metav1.ConditionTrue, vectorv1alpha1.ReasonCleanupInProgress, step.message)
+		if err := k8s.UpdateStatus(ctx, v, r.Client); err != nil {
+			return err
+		}
+		if err := step.run(ctx); err != nil {
+			log.Error(err, "Failed to cleanup Vector resources")
+			v.SetCondition(vectorv1alpha1.ConditionTerminating, metav1.ConditionTrue, vectorv1alpha1.ReasonCleanupFailed, err.Error())
+			if err := k8s.UpdateStatus(ctx, v, r.Client); err != nil {
+				log.Error(err, "Failed to update Vector status")
+			}
+			return err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(v, vectorv1alpha1.VectorFinalizer)
+	if err := r.Update(ctx, v); err != nil {
+		return err
+	}
+	metrics.DeleteVector(v.Namespace, v.Name)
+	return nil
+}
+
+// recordConfigMetrics records size and numbers of components of generated Vector config
+func recordConfigMetrics(v *vectorv1alpha1.Vector, role string, cfg []byte, b *config.Builder) {
+	metrics.SetConfigSize(v.Namespace, v.Name, role, cfg)
+	beforeMerge, afterMerge := b.GetComponentsCount()
+	metrics.SetConfigComponents(v.Namespace, v.Name, role, metrics.StageBeforeMerge, beforeMerge.Sources, beforeMerge.Transforms, beforeMerge.Sinks)
+	metrics.SetConfigComponents(v.Namespace, v.Name, role, metrics.StageAfterMerge, afterMerge.Sources, afterMerge.Transforms, afterMerge.Sinks)
 }
@@ -0,0 +1,35 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vectorv1beta1 "github.com/kaasops/vector-operator/api/v1beta1"
+)
+
+// SetupConversionWebhookWithManager registers /convert webhook, that converts Vector, VectorPipeline and
+// ClusterVectorPipeline between v1beta1 and storage version v1alpha1. Both versions must be added to manager scheme
+func SetupConversionWebhookWithManager(mgr ctrl.Manager) error {
+	for _, obj := range []client.Object{&vectorv1beta1.Vector{}, &vectorv1beta1.VectorPipeline{}, &vectorv1beta1.ClusterVectorPipeline{}} {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(obj).Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
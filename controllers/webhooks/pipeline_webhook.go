@@ -0,0 +1,76 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/config"
+	"github.com/kaasops/vector-operator/controllers/factory/pipeline"
+)
+
+//+kubebuilder:webhook:path=/validate-observability-kaasops-io-v1alpha1-vectorpipeline,mutating=false,failurePolicy=fail,sideEffects=None,groups=observability.kaasops.io,resources=vectorpipelines,verbs=create;update,versions=v1alpha1,name=vvectorpipeline.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-observability-kaasops-io-v1alpha1-clustervectorpipeline,mutating=false,failurePolicy=fail,sideEffects=None,groups=observability.kaasops.io,resources=clustervectorpipelines,verbs=create;update,versions=v1alpha1,name=vclustervectorpipeline.kb.io,admissionReviewVersions=v1
+
+// PipelineValidator validates VectorPipeline and ClusterVectorPipeline on create and update
+// with the same rules, that are used on config build
+type PipelineValidator struct{}
+
+// SetupPipelineWebhookWithManager registers validating webhooks for VectorPipeline and ClusterVectorPipeline
+func SetupPipelineWebhookWithManager(mgr ctrl.Manager) error {
+	for _, obj := range []runtime.Object{&vectorv1alpha1.VectorPipeline{}, &vectorv1alpha1.ClusterVectorPipeline{}} {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(obj).WithValidator(&PipelineValidator{}).Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *PipelineValidator) ValidateCreate(_ context.Context, obj runtime.Object) error {
+	return v.validate(obj)
+}
+
+func (v *PipelineValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) error {
+	return v.validate(newObj)
+}
+
+func (v *PipelineValidator) ValidateDelete(_ context.Context, _ runtime.Object) error {
+	return nil
+}
+
+func (v *PipelineValidator) validate(obj runtime.Object) error {
+	p, ok := obj.(pipeline.Pipeline)
+	if !ok {
+		return fmt.Errorf("expected pipeline, got %T", obj)
+	}
+	// Allow finalizers removal for pipelines with outdated spec
+	if p.IsDeleted() {
+		return nil
+	}
+	errs := config.ValidatePipeline(p)
+	if len(errs) == 0 {
+		return nil
+	}
+	return api_errors.NewInvalid(schema.GroupKind{Group: vectorv1alpha1.GroupVersion.Group, Kind: p.Type()}, p.GetName(), errs)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks_test
+
+import (
+	"context"
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/webhooks"
+	"github.com/stretchr/testify/require"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newPipelineSpec(sources, transforms, sinks string) vectorv1alpha1.VectorPipelineSpec {
+	spec := vectorv1alpha1.VectorPipelineSpec{}
+	if sources != "" {
+		spec.Sources = &runtime.RawExtension{Raw: []byte(sources)}
+	}
+	if transforms != "" {
+		spec.Transforms = &runtime.RawExtension{Raw: []byte(transforms)}
+	}
+	if sinks != "" {
+		spec.Sinks = &runtime.RawExtension{Raw: []byte(sinks)}
+	}
+	return spec
+}
+
+func TestPipelineValidator(t *testing.T) {
+	const validSink = `{"sink":{"type":"console","inputs":["source"],"encoding":{"codec":"json"}}}`
+
+	type testCase struct {
+		name    string
+		cluster bool
+		spec    vectorv1alpha1.VectorPipelineSpec
+		wantErr []string
+	}
+
+	cases := []testCase{
+		{
+			name: "Valid pipeline",
+			spec: newPipelineSpec(`{"source":{"type":"kubernetes_logs","extra_namespace_label_selector":"kubernetes.io/metadata.name=test"}}`, "", validSink),
+		},
+		{
+			name:    "Source type not allowed",
+			spec:    newPipelineSpec(`{"source":{"type":"file"}}`, "", validSink),
+			wantErr: []string{"spec.sources.source", "type kubernetes_logs only allowed"},
+		},
+		{
+			name:    "Cluster pipeline source type allowed",
+			cluster: true,
+			spec:    newPipelineSpec(`{"source":{"type":"file"}}`, "", validSink),
+		},
+		{
+			name:    "External namespace",
+			spec:    newPipelineSpec(`{"source":{"type":"kubernetes_logs","extra_namespace_label_selector":"kubernetes.io/metadata.name=other"}}`, "", validSink),
+			wantErr: []string{"spec.sources.source", "logs from external namespace not allowed"},
+		},
+		{
+			name:    "Missing type",
+			spec:    newPipelineSpec(`{"source":{"extra_label_selector":"app=test"}}`, "", validSink),
+			wantErr: []string{"spec.sources.source.type"},
+		},
+		{
+			name:    "Missing inputs",
+			spec:    newPipelineSpec(`{"source":{"type":"kubernetes_logs"}}`, `{"remap":{"type":"remap","source":"."}}`, validSink),
+			wantErr: []string{"spec.transforms.remap.inputs"},
+		},
+		{
+			name:    "Component is not an object",
+			spec:    newPipelineSpec(`{"source":"kubernetes_logs"}`, "", validSink),
+			wantErr: []string{"spec.sources.source", "must be an object"},
+		},
+		{
+			name:    "Dangling input",
+			spec:    newPipelineSpec(`{"source":{"type":"kubernetes_logs"}}`, "", `{"sink":{"type":"console","inputs":["source","sourc"]}}`),
+			wantErr: []string{`input "sourc" doesn't match any source or transform`},
+		},
+		{
+			name: "Typed components",
+			spec: vectorv1alpha1.VectorPipelineSpec{
+				TypedSources: map[string]vectorv1alpha1.SourceSpec{
+					"source": {KubernetesLogs: &vectorv1alpha1.KubernetesLogsSource{ExtraLabelSelector: "app=test"}},
+				},
+				Sinks: &runtime.RawExtension{Raw: []byte(validSink)},
+			},
+		},
+		{
+			name: "Typed component without type",
+			spec: vectorv1alpha1.VectorPipelineSpec{
+				TypedSources: map[string]vectorv1alpha1.SourceSpec{"source": {}},
+			},
+			wantErr: []string{"spec.sources", "source: component type is not set"},
+		},
+		{
+			name: "Template transform",
+			spec: newPipelineSpec(
+				`{"source":{"type":"kubernetes_logs"}}`,
+				`{"json":{"type":"template","name":"parse-json","inputs":["source"]}}`,
+				`{"sink":{"type":"console","inputs":["json"],"encoding":{"codec":"json"}}}`,
+			),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var obj runtime.Object = &vectorv1alpha1.VectorPipeline{
+				TypeMeta:   metav1.TypeMeta{Kind: vectorv1alpha1.LocalPipelineKind},
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"},
+				Spec:       tc.spec,
+			}
+			if tc.cluster {
+				obj = &vectorv1alpha1.ClusterVectorPipeline{
+					TypeMeta:   metav1.TypeMeta{Kind: vectorv1alpha1.ClusterPipelineKind},
+					ObjectMeta: metav1.ObjectMeta{Name: "test"},
+					Spec:       tc.spec,
+				}
+			}
+
+			err := (&webhooks.PipelineValidator{}).ValidateCreate(context.Background(), obj)
+			if len(tc.wantErr) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, api_errors.IsInvalid(err))
+			for _, want := range tc.wantErr {
+				require.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
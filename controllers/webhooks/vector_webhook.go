@@ -0,0 +1,72 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoragent"
+	"github.com/kaasops/vector-operator/controllers/factory/vector/vectoraggregator"
+)
+
+//+kubebuilder:webhook:path=/mutate-observability-kaasops-io-v1alpha1-vector,mutating=true,failurePolicy=fail,sideEffects=None,groups=observability.kaasops.io,resources=vectors,verbs=create;update,versions=v1alpha1,name=mvector.kb.io,admissionReviewVersions=v1
+
+// VectorDefaulter persists defaults of Vector Agent and Vector Aggregator into Vector spec.
+// Operator version, that changed spec, is recorded in DefaultsVersionAnnotation
+type VectorDefaulter struct {
+	OperatorVersion string
+}
+
+// SetupVectorWebhookWithManager registers defaulting webhook for Vector
+func SetupVectorWebhookWithManager(mgr ctrl.Manager, operatorVersion string) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&vectorv1alpha1.Vector{}).
+		WithDefaulter(&VectorDefaulter{OperatorVersion: operatorVersion}).
+		Complete()
+}
+
+func (d *VectorDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	v, ok := obj.(*vectorv1alpha1.Vector)
+	if !ok {
+		return fmt.Errorf("expected Vector, got %T", obj)
+	}
+	if !v.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	spec := v.Spec.DeepCopy()
+	// Same defaults are applied by controllers on reconcile
+	vectoragent.NewController(v, nil, nil).SetDefault()
+	if v.IsAggregatorEnabled() {
+		vectoraggregator.NewController(v, nil, nil).SetDefault()
+	}
+	if equality.Semantic.DeepEqual(spec, &v.Spec) {
+		return nil
+	}
+
+	if v.Annotations == nil {
+		v.Annotations = make(map[string]string)
+	}
+	v.Annotations[vectorv1alpha1.DefaultsVersionAnnotation] = d.OperatorVersion
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks_test
+
+import (
+	"context"
+	"testing"
+
+	vectorv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	"github.com/kaasops/vector-operator/controllers/webhooks"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVectorDefaulter(t *testing.T) {
+	type testCase struct {
+		name           string
+		vector         *vectorv1alpha1.Vector
+		wantAgentImage string
+		wantAggregator bool
+		wantAnnotation string
+	}
+
+	cases := []testCase{
+		{
+			name: "Empty spec",
+			vector: &vectorv1alpha1.Vector{
+				ObjectMeta: metav1.ObjectMeta{Name: "vector", Namespace: "test"},
+			},
+			wantAgentImage: "timberio/vector:0.28.1-distroless-libc",
+			wantAnnotation: "v1.0.0",
+		},
+		{
+			name: "Custom image is kept",
+			vector: &vectorv1alpha1.Vector{
+				ObjectMeta: metav1.ObjectMeta{Name: "vector", Namespace: "test"},
+				Spec: vectorv1alpha1.VectorSpec{
+					Agent: &vectorv1alpha1.VectorAgent{Image: "timberio/vector:0.27.0-debian"},
+				},
+			},
+			wantAgentImage: "timberio/vector:0.27.0-debian",
+			wantAnnotation: "v1.0.0",
+		},
+		{
+			name: "Aggregator defaults",
+			vector: &vectorv1alpha1.Vector{
+				ObjectMeta: metav1.ObjectMeta{Name: "vector", Namespace: "test"},
+				Spec: vectorv1alpha1.VectorSpec{
+					Aggregator: &vectorv1alpha1.VectorAggregator{Enable: true},
+				},
+			},
+			wantAgentImage: "timberio/vector:0.28.1-distroless-libc",
+			wantAggregator: true,
+			wantAnnotation: "v1.0.0",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &webhooks.VectorDefaulter{OperatorVersion: "v1.0.0"}
+			require.NoError(t, d.Default(context.Background(), tc.vector))
+			require.Equal(t, tc.wantAgentImage, tc.vector.Spec.Agent.Image)
+			require.NotEmpty(t, tc.vector.Spec.Agent.Volumes)
+			require.Equal(t, tc.wantAnnotation, tc.vector.Annotations[vectorv1alpha1.DefaultsVersionAnnotation])
+			if tc.wantAggregator {
+				require.NotNil(t, tc.vector.Spec.Aggregator.Replicas)
+				require.NotEmpty(t, tc.vector.Spec.Aggregator.Image)
+			}
+		})
+	}
+}
+
+func TestVectorDefaulterKeepsVersion(t *testing.T) {
+	v := &vectorv1alpha1.Vector{ObjectMeta: metav1.ObjectMeta{Name: "vector", Namespace: "test"}}
+	require.NoError(t, (&webhooks.VectorDefaulter{OperatorVersion: "v1.0.0"}).Default(context.Background(), v))
+
+	// Spec already has all defaults, newer operator doesn't change it
+	require.NoError(t, (&webhooks.VectorDefaulter{OperatorVersion: "v1.1.0"}).Default(context.Background(), v))
+	require.Equal(t, "v1.0.0", v.Annotations[vectorv1alpha1.DefaultsVersionAnnotation])
+}
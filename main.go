@@ -19,7 +19,6 @@ package main
 import (
 	"flag"
 	"os"
-	"sync"
 	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -42,8 +41,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	observabilityv1alpha1 "github.com/kaasops/vector-operator/api/v1alpha1"
+	observabilityv1beta1 "github.com/kaasops/vector-operator/api/v1beta1"
 	"github.com/kaasops/vector-operator/controllers"
+	"github.com/kaasops/vector-operator/controllers/factory/config/configcheck"
+	"github.com/kaasops/vector-operator/controllers/factory/metrics"
+	"github.com/kaasops/vector-operator/controllers/factory/pipeline"
 	"github.com/kaasops/vector-operator/controllers/factory/utils/k8s"
+	"github.com/kaasops/vector-operator/controllers/webhooks"
 	monitorv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	//+kubebuilder:scaffold:imports
 )
@@ -51,12 +55,16 @@ import (
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
+
+	// version is set on build with -ldflags "-X main.version=<version>"
+	version = "dev"
 )
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(observabilityv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(observabilityv1beta1.AddToScheme(scheme))
 	utilruntime.Must(monitorv1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
@@ -67,10 +75,13 @@ func main() {
 	var probeAddr string
 	var namespace string
 	var watchLabel string
-	var pipelineCheckWG sync.WaitGroup
-	var PipelineCheckTimeout time.Duration
-	var PipelineDeleteEventTimeout time.Duration
+	var PipelineCheckWindow time.Duration
+	var PipelineCheckMaxWait time.Duration
 	var ConfigCheckTimeout time.Duration
+	var ConfigCheckCleanupInterval time.Duration
+	var ConfigCheckMaxInFlight int
+	var enableWebhooks bool
+	var AllowUnknownComponentTypes bool
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -79,9 +90,15 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&namespace, "watch-namespace", "", "Namespace to filter the list of watched objects")
 	flag.StringVar(&watchLabel, "watch-name", "", "Filter the list of watched objects by checking the app.kubernetes.io/managed-by label")
-	flag.DurationVar(&PipelineCheckTimeout, "pipeline-check-timeout", 15*time.Second, "wait pipeline checks before force vector reconcile. Default: 15s")
-	flag.DurationVar(&PipelineDeleteEventTimeout, "pipeline-delete-timeout", 5*time.Second, "collect delete events timeout")
+	flag.DurationVar(&PipelineCheckWindow, "pipeline-check-window", 5*time.Second, "collect pipeline changes, until no changes are made during window, and check them in one configcheck pod per Vector")
+	flag.DurationVar(&PipelineCheckMaxWait, "pipeline-check-max-wait", 30*time.Second, "max time of collecting pipeline changes for one config check")
+	flag.Duration("pipeline-check-timeout", 15*time.Second, "deprecated: pipeline changes are batched, see --pipeline-check-window")
+	flag.Duration("pipeline-delete-timeout", 5*time.Second, "deprecated: pipeline deletions are batched, see --pipeline-check-window")
 	flag.DurationVar(&ConfigCheckTimeout, "configcheck-timeout", 300*time.Second, "configcheck timeout")
+	flag.DurationVar(&ConfigCheckCleanupInterval, "configcheck-cleanup-interval", 10*time.Minute, "interval of removing orphaned configcheck pods and secrets older than configcheck timeout")
+	flag.IntVar(&ConfigCheckMaxInFlight, "configcheck-max-in-flight", 5, "max number of configcheck pods running at once, Vector checks are started before pipeline checks. 0 - unlimited")
+	flag.BoolVar(&AllowUnknownComponentTypes, "allow-unknown-component-types", false, "don't reject component types unknown to operator before configcheck pod, e.g. for Vector image newer than operator")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false, "Enable admission webhooks on port 9443. TLS certificate is required")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -125,29 +142,71 @@ func main() {
 		os.Exit(1)
 	}
 
+	pipelineChecks := pipeline.NewBatcher(PipelineCheckWindow, PipelineCheckMaxWait)
+	configCheckQueue := configcheck.NewQueue(ConfigCheckMaxInFlight)
 	if err = (&controllers.VectorReconciler{
-		Client:               mgr.GetClient(),
-		Scheme:               mgr.GetScheme(),
-		Clientset:            clientset,
-		PipelineCheckWG:      &pipelineCheckWG,
-		PipelineCheckTimeout: PipelineCheckTimeout,
-		ConfigCheckTimeout:   ConfigCheckTimeout,
-		DiscoveryClient:      dc,
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		Clientset:          clientset,
+		PipelineChecks:     pipelineChecks,
+		ConfigCheckTimeout: ConfigCheckTimeout,
+		ConfigCheckQueue:   configCheckQueue,
+		DiscoveryClient:    dc,
+		Recorder:           mgr.GetEventRecorderFor("vector-operator"),
+
+		AllowUnknownComponentTypes: AllowUnknownComponentTypes,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Vector")
 		os.Exit(1)
 	}
 	if err = (&controllers.PipelineReconciler{
-		Client:                     mgr.GetClient(),
-		Scheme:                     mgr.GetScheme(),
-		Clientset:                  clientset,
-		PipelineCheckWG:            &pipelineCheckWG,
-		PipelineDeleteEventTimeout: PipelineDeleteEventTimeout,
-		ConfigCheckTimeout:         ConfigCheckTimeout,
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		Clientset:          clientset,
+		PipelineChecks:     pipelineChecks,
+		ConfigCheckTimeout: ConfigCheckTimeout,
+		ConfigCheckQueue:   configCheckQueue,
+		Recorder:           mgr.GetEventRecorderFor("vector-operator"),
+
+		AllowUnknownComponentTypes: AllowUnknownComponentTypes,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "VectorPipeline")
 		os.Exit(1)
 	}
+	if err = (&controllers.TransformTemplateReconciler{
+		Client:         mgr.GetClient(),
+		PipelineChecks: pipelineChecks,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "VectorTransformTemplate")
+		os.Exit(1)
+	}
+	if enableWebhooks {
+		if err = webhooks.SetupPipelineWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "VectorPipeline")
+			os.Exit(1)
+		}
+		if err = webhooks.SetupVectorWebhookWithManager(mgr, version); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Vector")
+			os.Exit(1)
+		}
+		if err = webhooks.SetupConversionWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "conversion")
+			os.Exit(1)
+		}
+	}
+	if err = mgr.Add(&configcheck.Janitor{
+		ClientSet: clientset,
+		Namespace: namespace,
+		MaxAge:    ConfigCheckTimeout,
+		Interval:  ConfigCheckCleanupInterval,
+	}); err != nil {
+		setupLog.Error(err, "unable to set up configcheck janitor")
+		os.Exit(1)
+	}
+	if err = metrics.RegisterPipelineCollector(mgr.GetClient()); err != nil {
+		setupLog.Error(err, "unable to register pipeline metrics")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -159,7 +218,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	setupLog.Info("starting manager")
+	setupLog.Info("starting manager", "version", version)
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)